@@ -0,0 +1,194 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditedFields are the task fields whose changes are recorded in
+// TaskEvents, by JSON name. Labels, checklists and comments have routes
+// of their own and are not part of the audit trail.
+var auditedFields = []struct {
+	name  string
+	value func(*Task) any
+}{
+	{"title", func(t *Task) any { return t.Title }},
+	{"description", func(t *Task) any { return t.Description }},
+	{"priority", func(t *Task) any { return t.Priority }},
+	{"due_at", func(t *Task) any {
+		if t.DueAt == nil {
+			return nil
+		}
+		// Compare instants, whatever zone they were written in.
+		return t.DueAt.UTC()
+	}},
+	{"completed", func(t *Task) any { return t.Completed }},
+	{"auto_complete", func(t *Task) any { return t.AutoComplete }},
+	{"recurrence", func(t *Task) any { return t.Recurrence }},
+	{"board_id", func(t *Task) any { return t.BoardID }},
+	{"column_id", func(t *Task) any { return t.ColumnID }},
+	{"position", func(t *Task) any { return t.Position }},
+	{"assignee_id", func(t *Task) any { return t.AssigneeID }},
+	{"watcher_ids", func(t *Task) any {
+		ids := make([]uint, len(t.Watchers))
+		for i, watcher := range t.Watchers {
+			ids[i] = watcher.ID
+		}
+		slices.Sort(ids)
+		return ids
+	}},
+}
+
+// diffTasks lists the audited fields whose values differ between before
+// and after, where nil stands for a task that does not exist.
+func diffTasks(before, after *Task) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range auditedFields {
+		old, value := fieldJSON(before, field.value), fieldJSON(after, field.value)
+		if !bytes.Equal(old, value) {
+			changes = append(changes, FieldChange{Field: field.name, Before: old, After: value})
+		}
+	}
+	return changes
+}
+
+// fieldJSON encodes a field of task, or null when there is no task.
+func fieldJSON(task *Task, value func(*Task) any) json.RawMessage {
+	if task == nil {
+		return json.RawMessage("null")
+	}
+	data, err := json.Marshal(value(task))
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// recordTaskEvent appends the change of a task from before to after to
+// the audit trail, on behalf of the actor and request of ctx, and to the
+// outbox, or publishes it to the hub right away when there is none. Either
+// task may be nil for creations and deletions; updates that change no
+// audited field are published but not recorded. Called with the context
+// of the transaction making the change, the event commits or rolls back
+// with it.
+func (s *Server) recordTaskEvent(ctx context.Context, eventType string, before, after *Task) error {
+	task := after
+	if task == nil {
+		task = before
+	}
+	event := HubEvent{Type: eventType, WorkspaceID: task.WorkspaceID, Task: task}
+	for _, t := range []*Task{before, after} {
+		if t != nil && t.BoardID != nil && !slices.Contains(event.BoardIDs, *t.BoardID) {
+			event.BoardIDs = append(event.BoardIDs, *t.BoardID)
+		}
+	}
+	if s.outbox != nil {
+		if err := s.appendOutbox(ctx, event); err != nil {
+			return err
+		}
+	} else {
+		s.publish(ctx, event)
+	}
+
+	if s.activity == nil {
+		return nil
+	}
+
+	audit := TaskEvent{Type: eventType, RequestID: requestIDFrom(ctx), Changes: diffTasks(before, after)}
+	if eventType == TaskUpdated && len(audit.Changes) == 0 {
+		return nil
+	}
+	audit.TaskID = task.ID
+	if actorID, ok := actorFrom(ctx); ok {
+		audit.ActorID = &actorID
+	}
+	return s.activity.RecordEvent(ctx, &audit)
+}
+
+// listEvents fetches one page of events, setting the Link header of the
+// next page. It writes the error response and returns false on failure.
+func (s *Server) listEvents(c *gin.Context, opts EventListOptions) ([]TaskEvent, bool) {
+	// Fetch one extra event to find out whether another page follows.
+	pageSize := opts.Limit
+	opts.Limit++
+
+	var events []TaskEvent
+	err := TrackDBOperation(c.Request.Context(), "query_task_events", func(ctx context.Context) error {
+		var err error
+		events, err = s.activity.ListEvents(ctx, opts)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch activity")
+		return nil, false
+	}
+
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		setNextLink(c, encodeEventCursor(events[pageSize-1].ID))
+	}
+	return events, true
+}
+
+// taskHistory returns the audit trail of a task, newest first and
+// paginated like GET /tasks. The history of deleted tasks stays readable.
+func (s *Server) taskHistory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	opts, err := parseEventQuery(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.TaskID = id
+
+	events, ok := s.listEvents(c, opts)
+	if !ok {
+		return
+	}
+
+	// Tasks older than the audit trail have no events; others are unknown.
+	if len(events) == 0 && opts.Before == 0 {
+		err := TrackDBOperation(c.Request.Context(), "find_task", func(ctx context.Context) error {
+			_, err := s.tasks.Get(ctx, id)
+			if errors.Is(err, ErrTaskNotFound) {
+				_, err = s.tasks.GetDeleted(ctx, id)
+			}
+			return err
+		})
+		switch {
+		case errors.Is(err, ErrTaskNotFound):
+			writeError(c, http.StatusNotFound, "task not found")
+			return
+		case err != nil:
+			writeInternalError(c, err, "failed to fetch task")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// listActivity returns the events of every task of the workspace, newest
+// first and paginated like GET /tasks.
+func (s *Server) listActivity(c *gin.Context) {
+	opts, err := parseEventQuery(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if events, ok := s.listEvents(c, opts); ok {
+		c.JSON(http.StatusOK, events)
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// errEventImmutable is returned when something tries to change or remove
+// a recorded TaskEvent.
+var errEventImmutable = errors.New("task events cannot be changed")
+
+// BeforeUpdate keeps recorded events immutable.
+func (*TaskEvent) BeforeUpdate(*gorm.DB) error {
+	return errEventImmutable
+}
+
+// BeforeDelete keeps recorded events immutable.
+func (*TaskEvent) BeforeDelete(*gorm.DB) error {
+	return errEventImmutable
+}
+
+// EventListOptions selects a page of the activity log.
+type EventListOptions struct {
+	// TaskID limits the events to those of one task; zero means every
+	// task of the workspace.
+	TaskID uint
+	// Limit caps the number of returned events; zero means no limit.
+	Limit int
+	// Before resumes the listing with the events older than the one with
+	// this ID.
+	Before uint
+}
+
+// ActivityStore persists the audit trail of tasks. Events are written in
+// the transaction of the change they record when the context carries one;
+// see Transactor.
+type ActivityStore interface {
+	// RecordEvent appends an event to the log, filling in its ID and time.
+	RecordEvent(ctx context.Context, event *TaskEvent) error
+	// ListEvents returns the events selected by opts, newest first.
+	ListEvents(ctx context.Context, opts EventListOptions) ([]TaskEvent, error)
+}
+
+// GormActivityStore is an ActivityStore backed by a GORM database connection.
+type GormActivityStore struct {
+	db *gorm.DB
+}
+
+// NewGormActivityStore returns an ActivityStore that persists events through db.
+func NewGormActivityStore(db *gorm.DB) *GormActivityStore {
+	return &GormActivityStore{db: db}
+}
+
+// RecordEvent inserts an event.
+func (s *GormActivityStore) RecordEvent(ctx context.Context, event *TaskEvent) error {
+	return dbFor(ctx, s.db).Create(event).Error
+}
+
+// ListEvents returns a page of events ordered by descending ID, which is
+// the order they were recorded in.
+func (s *GormActivityStore) ListEvents(ctx context.Context, opts EventListOptions) ([]TaskEvent, error) {
+	query := dbFor(ctx, s.db).Order("id desc")
+	if opts.TaskID != 0 {
+		query = query.Where("task_id = ?", opts.TaskID)
+	}
+	if opts.Before != 0 {
+		query = query.Where("id < ?", opts.Before)
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	events := []TaskEvent{}
+	err := query.Find(&events).Error
+	return events, err
+}
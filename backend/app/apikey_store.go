@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAPIKeyNotFound is returned when no API key of the user matches the
+// requested ID, or no key matches a presented secret.
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// APIKeyStore persists the API keys of users.
+type APIKeyStore interface {
+	// ListAPIKeys returns the keys of a user, newest first.
+	ListAPIKeys(ctx context.Context, userID uint) ([]APIKey, error)
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	// DeleteAPIKey revokes a key of the given user.
+	DeleteAPIKey(ctx context.Context, userID, id uint) error
+	// FindAPIKey returns the key with the given hash and its user.
+	FindAPIKey(ctx context.Context, hash string) (*APIKey, error)
+	// TouchAPIKey records that a key was used at the given time.
+	TouchAPIKey(ctx context.Context, id uint, at time.Time) error
+}
+
+// GormAPIKeyStore is an APIKeyStore backed by a GORM database connection.
+type GormAPIKeyStore struct {
+	db *gorm.DB
+}
+
+// NewGormAPIKeyStore returns an APIKeyStore that persists keys through db.
+func NewGormAPIKeyStore(db *gorm.DB) *GormAPIKeyStore {
+	return &GormAPIKeyStore{db: db}
+}
+
+// ListAPIKeys returns the keys of a user, newest first.
+func (s *GormAPIKeyStore) ListAPIKeys(ctx context.Context, userID uint) ([]APIKey, error) {
+	keys := []APIKey{}
+	err := dbFor(ctx, s.db).Where("user_id = ?", userID).Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// CreateAPIKey inserts a new key.
+func (s *GormAPIKeyStore) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	return dbFor(ctx, s.db).Omit("User").Create(key).Error
+}
+
+// DeleteAPIKey removes a key of the given user.
+func (s *GormAPIKeyStore) DeleteAPIKey(ctx context.Context, userID, id uint) error {
+	res := dbFor(ctx, s.db).Where("user_id = ?", userID).Delete(&APIKey{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// FindAPIKey returns the key with the given hash together with its user.
+func (s *GormAPIKeyStore) FindAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	var key APIKey
+	err := dbFor(ctx, s.db).Preload("User").Where("hash = ?", hash).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchAPIKey sets the last-used time of a key.
+func (s *GormAPIKeyStore) TouchAPIKey(ctx context.Context, id uint, at time.Time) error {
+	return dbFor(ctx, s.db).Model(&APIKey{ID: id}).Update("last_used_at", at).Error
+}
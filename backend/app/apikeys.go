@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// apiKeyPrefix starts every API key, telling them apart from JWTs in
+	// the Authorization header.
+	apiKeyPrefix = "tbk_"
+	// apiKeyTouchInterval limits how often the last-used time of a key
+	// is written.
+	apiKeyTouchInterval = time.Minute
+)
+
+// generateAPIKey returns a new random API key.
+func generateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashAPIKey returns the stored form of key. Keys are random enough that
+// a fast hash does not make them guessable.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// identifyAPIKey returns the identity of the user owning key.
+func (s *Server) identifyAPIKey(ctx context.Context, key string) (*Identity, error) {
+	if s.keys == nil {
+		return nil, errors.New("invalid API key")
+	}
+
+	apiKey, err := s.keys.FindAPIKey(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, errors.New("invalid API key")
+	}
+
+	now := time.Now()
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, errors.New("API key expired")
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		// Failing to record the last use must not fail the request.
+		_ = s.keys.TouchAPIKey(ctx, apiKey.ID, now)
+	}
+
+	return &Identity{
+		UserID:      apiKey.UserID,
+		WorkspaceID: apiKey.User.WorkspaceID,
+		Email:       apiKey.User.Email,
+		Name:        apiKey.User.Name,
+		Scopes:      apiKey.Scopes,
+	}, nil
+}
+
+// writeAPIKeyError maps APIKeyStore errors to HTTP responses.
+func writeAPIKeyError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		writeError(c, http.StatusNotFound, "API key not found")
+		return
+	}
+	writeInternalError(c, err, fallback)
+}
+
+// listAPIKeys returns the API keys of the authenticated user.
+func (s *Server) listAPIKeys(c *gin.Context) {
+	userID, _ := currentUserID(c)
+
+	var keys []APIKey
+	err := TrackDBOperation(c.Request.Context(), "query_api_keys", func(ctx context.Context) error {
+		var err error
+		keys, err = s.keys.ListAPIKeys(ctx, userID)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch API keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKeyInput represents the expected payload for creating an API key.
+// A key without scopes has the same access as its user.
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" binding:"omitempty,dive,oneof=tasks:read tasks:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey is returned once, when a key is created; Key is the only
+// copy of the secret.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// createAPIKey issues a new API key for the authenticated user.
+func (s *Server) createAPIKey(c *gin.Context) {
+	var input CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		writeError(c, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	secret, err := generateAPIKey()
+	if err != nil {
+		writeInternalError(c, err, "failed to create API key")
+		return
+	}
+
+	created := CreatedAPIKey{
+		APIKey: APIKey{
+			UserID:    userID,
+			Name:      input.Name,
+			Prefix:    secret[:len(apiKeyPrefix)+8],
+			Hash:      hashAPIKey(secret),
+			Scopes:    ScopeList(input.Scopes),
+			ExpiresAt: input.ExpiresAt,
+		},
+		Key: secret,
+	}
+	if created.Scopes == nil {
+		created.Scopes = ScopeList{}
+	}
+
+	err = TrackDBOperation(c.Request.Context(), "create_api_key", func(ctx context.Context) error {
+		return s.keys.CreateAPIKey(ctx, &created.APIKey)
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to create API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// deleteAPIKey revokes one of the authenticated user's API keys.
+func (s *Server) deleteAPIKey(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, _ := currentUserID(c)
+
+	err := TrackDBOperation(c.Request.Context(), "delete_api_key", func(ctx context.Context) error {
+		return s.keys.DeleteAPIKey(ctx, userID, id)
+	})
+
+	if err != nil {
+		writeAPIKeyError(c, err, "failed to revoke API key")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
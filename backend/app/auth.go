@@ -0,0 +1,237 @@
+package app
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms supported for issued tokens.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// Token types, recorded in the typ claim so that a refresh token cannot
+// be used as an access token and vice versa.
+const (
+	tokenAccess  = "access"
+	tokenRefresh = "refresh"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed, signed
+	// with another key or algorithm, or of the wrong type.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for well-formed tokens past their expiry.
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// AuthConfig describes how tokens are signed. A private key file selects
+// RS256; otherwise tokens are signed with HS256 using Secret.
+type AuthConfig struct {
+	Secret     string
+	KeyFile    string // PEM encoded RSA private key, PKCS #1 or PKCS #8
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// LoadAuthConfig reads the token settings from environment variables.
+func LoadAuthConfig() (AuthConfig, error) {
+	cfg := AuthConfig{
+		Secret:  os.Getenv("JWT_SECRET"),
+		KeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		Issuer:  getEnv("JWT_ISSUER", "taskboard"),
+	}
+
+	var err error
+	if cfg.AccessTTL, err = time.ParseDuration(getEnv("JWT_ACCESS_TTL", "15m")); err != nil {
+		return cfg, fmt.Errorf("invalid JWT_ACCESS_TTL: %w", err)
+	}
+	if cfg.RefreshTTL, err = time.ParseDuration(getEnv("JWT_REFRESH_TTL", "168h")); err != nil {
+		return cfg, fmt.Errorf("invalid JWT_REFRESH_TTL: %w", err)
+	}
+	return cfg, nil
+}
+
+// Identity is the authenticated user a request acts on behalf of, and the
+// workspace it is limited to. Scopes is set when the request authenticated
+// with a restricted API key.
+type Identity struct {
+	UserID      uint
+	WorkspaceID uint
+	Email       string
+	Name        string
+	Scopes      ScopeList
+}
+
+// tokenClaims is the JWT payload of access and refresh tokens.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Type      string `json:"typ"`
+	Workspace uint   `json:"wid"`
+	Email     string `json:"email,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// TokenPair is returned by the login and refresh endpoints.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // seconds until the access token expires
+}
+
+// Authenticator issues and verifies signed JWTs.
+type Authenticator struct {
+	alg        string
+	secret     []byte
+	key        *rsa.PrivateKey
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewAuthenticator returns an Authenticator for cfg. Either a secret or a
+// key file is required.
+func NewAuthenticator(cfg AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		issuer:     cfg.Issuer,
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
+	}
+
+	switch {
+	case cfg.KeyFile != "":
+		key, err := loadRSAKey(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		a.alg, a.key = AlgRS256, key
+	case cfg.Secret != "":
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("JWT_SECRET must be at least 32 bytes long")
+		}
+		a.alg, a.secret = AlgHS256, []byte(cfg.Secret)
+	default:
+		return nil, errors.New("JWT_SECRET or JWT_PRIVATE_KEY_FILE must be set")
+	}
+
+	if a.accessTTL <= 0 || a.refreshTTL <= 0 {
+		return nil, errors.New("token lifetimes must be positive")
+	}
+	return a, nil
+}
+
+// loadRSAKey reads a PEM encoded RSA private key.
+func loadRSAKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("JWT key file contains no PEM block")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse JWT key file: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("JWT key file does not hold an RSA key")
+	}
+	return key, nil
+}
+
+// IssueTokens returns a fresh access and refresh token for user.
+func (a *Authenticator) IssueTokens(user *User) (TokenPair, error) {
+	now := time.Now()
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   a.issuer,
+			Subject:  strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt: jwt.NewNumericDate(now),
+		},
+		Workspace: user.WorkspaceID,
+		Email:     user.Email,
+		Name:      user.Name,
+	}
+
+	access := claims
+	access.Type = tokenAccess
+	access.ExpiresAt = jwt.NewNumericDate(now.Add(a.accessTTL))
+
+	refresh := claims
+	refresh.Type = tokenRefresh
+	refresh.ExpiresAt = jwt.NewNumericDate(now.Add(a.refreshTTL))
+
+	pair := TokenPair{TokenType: "Bearer", ExpiresIn: int(a.accessTTL.Seconds())}
+	var err error
+	if pair.AccessToken, err = a.sign(access); err != nil {
+		return TokenPair{}, err
+	}
+	if pair.RefreshToken, err = a.sign(refresh); err != nil {
+		return TokenPair{}, err
+	}
+	return pair, nil
+}
+
+// sign encodes claims as a compact JWS.
+func (a *Authenticator) sign(claims tokenClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(a.alg), claims)
+	if a.key != nil {
+		return token.SignedString(a.key)
+	}
+	return token.SignedString(a.secret)
+}
+
+// verify checks the signature, issuer, type and expiry of token and
+// returns its claims. Tokens must use the configured algorithm.
+func (a *Authenticator) verify(token, tokenType string) (*tokenClaims, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, a.verificationKey,
+		jwt.WithValidMethods([]string{a.alg}),
+		jwt.WithIssuer(a.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired) && !errors.Is(err, jwt.ErrTokenInvalidIssuer) && claims.Type == tokenType:
+		return nil, ErrTokenExpired
+	case err != nil, claims.Type != tokenType:
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// verificationKey returns the key that checks the signature of a token
+// whose algorithm the parser has already checked.
+func (a *Authenticator) verificationKey(*jwt.Token) (any, error) {
+	if a.key != nil {
+		return &a.key.PublicKey, nil
+	}
+	return a.secret, nil
+}
+
+// identity returns the user a verified token was issued to. Tokens issued
+// before workspaces existed carry none and are rejected.
+func (c *tokenClaims) identity() (*Identity, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, strconv.IntSize)
+	if err != nil || id == 0 || c.Workspace == 0 {
+		return nil, ErrInvalidToken
+	}
+	return &Identity{UserID: uint(id), WorkspaceID: c.Workspace, Email: c.Email, Name: c.Name}, nil
+}
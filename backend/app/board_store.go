@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrBoardNotFound is returned when no board matches the requested ID.
+	ErrBoardNotFound = errors.New("board not found")
+	// ErrColumnNotFound is returned when no column of the board matches
+	// the requested ID.
+	ErrColumnNotFound = errors.New("column not found")
+	// ErrColumnNotEmpty is returned when deleting a column that still
+	// holds tasks.
+	ErrColumnNotEmpty = errors.New("column is not empty")
+)
+
+// BoardStore persists boards and their columns, and moves tasks between
+// columns. It shares the tasks table with the TaskStore of the same backend.
+type BoardStore interface {
+	ListBoards(ctx context.Context) ([]Board, error)
+	// GetBoard returns the board with its columns in display order.
+	GetBoard(ctx context.Context, id uint) (*Board, error)
+	CreateBoard(ctx context.Context, board *Board) error
+	UpdateBoard(ctx context.Context, board *Board) error
+	// DeleteBoard removes a board and its columns. Its tasks are kept
+	// but detached from the board.
+	DeleteBoard(ctx context.Context, id uint) error
+
+	// ListColumns returns the columns of a board in display order.
+	ListColumns(ctx context.Context, boardID uint) ([]Column, error)
+	GetColumn(ctx context.Context, boardID, columnID uint) (*Column, error)
+	// FindColumn returns a column of any board.
+	FindColumn(ctx context.Context, columnID uint) (*Column, error)
+	// CreateColumn adds a column to the end of its board.
+	CreateColumn(ctx context.Context, column *Column) error
+	UpdateColumn(ctx context.Context, column *Column) error
+	// DeleteColumn removes an empty column, or fails with ErrColumnNotEmpty.
+	DeleteColumn(ctx context.Context, boardID, columnID uint) error
+
+	// MoveTask places a task at position (zero-based) in a column,
+	// shifting the other tasks of the source and target columns so that
+	// positions stay contiguous, and syncs Completed with the column's
+	// Done flag. Positions past the end append the task.
+	MoveTask(ctx context.Context, taskID, columnID uint, position int) (*Task, error)
+}
+
+// GormBoardStore is a BoardStore backed by a GORM database connection.
+type GormBoardStore struct {
+	db *gorm.DB
+}
+
+// NewGormBoardStore returns a BoardStore that persists boards through db.
+func NewGormBoardStore(db *gorm.DB) *GormBoardStore {
+	return &GormBoardStore{db: db}
+}
+
+// orderedColumns sorts preloaded or queried columns for display.
+func orderedColumns(db *gorm.DB) *gorm.DB {
+	return db.Order("position asc, id asc")
+}
+
+// ListBoards returns every board ordered by creation.
+func (s *GormBoardStore) ListBoards(ctx context.Context) ([]Board, error) {
+	boards := []Board{}
+	err := dbFor(ctx, s.db).Order("id asc").Find(&boards).Error
+	return boards, err
+}
+
+// GetBoard returns the board with the given ID and its columns.
+func (s *GormBoardStore) GetBoard(ctx context.Context, id uint) (*Board, error) {
+	var board Board
+	err := dbFor(ctx, s.db).Preload("Columns", orderedColumns).First(&board, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBoardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// CreateBoard inserts a new board together with any columns it carries.
+func (s *GormBoardStore) CreateBoard(ctx context.Context, board *Board) error {
+	return dbFor(ctx, s.db).Create(board).Error
+}
+
+// UpdateBoard saves the board's own fields; columns are managed separately.
+func (s *GormBoardStore) UpdateBoard(ctx context.Context, board *Board) error {
+	return dbFor(ctx, s.db).Omit(clause.Associations).Save(board).Error
+}
+
+// DeleteBoard detaches the board's tasks and removes the board and its columns.
+func (s *GormBoardStore) DeleteBoard(ctx context.Context, id uint) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&Board{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrBoardNotFound
+		}
+
+		// Trashed tasks are detached too, so that they are restored off the board.
+		err := tx.Unscoped().Model(&Task{}).Where("board_id = ?", id).
+			Updates(map[string]any{"board_id": nil, "column_id": nil, "position": 0, "version": nextVersion}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("board_id = ?", id).Delete(&Column{}).Error
+	})
+}
+
+// ListColumns returns the columns of a board in display order.
+func (s *GormBoardStore) ListColumns(ctx context.Context, boardID uint) ([]Column, error) {
+	if _, err := s.GetBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+
+	columns := []Column{}
+	err := orderedColumns(dbFor(ctx, s.db)).Where("board_id = ?", boardID).Find(&columns).Error
+	return columns, err
+}
+
+// GetColumn returns a column of the given board.
+func (s *GormBoardStore) GetColumn(ctx context.Context, boardID, columnID uint) (*Column, error) {
+	var column Column
+	err := dbFor(ctx, s.db).Where("board_id = ?", boardID).First(&column, columnID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrColumnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
+
+// FindColumn returns the column with the given ID.
+func (s *GormBoardStore) FindColumn(ctx context.Context, columnID uint) (*Column, error) {
+	var column Column
+	err := dbFor(ctx, s.db).First(&column, columnID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrColumnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
+
+// CreateColumn appends a column to its board.
+func (s *GormBoardStore) CreateColumn(ctx context.Context, column *Column) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Board{}).Where("id = ?", column.BoardID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrBoardNotFound
+		}
+
+		var last struct{ Max *int }
+		if err := tx.Model(&Column{}).Select("MAX(position) AS max").
+			Where("board_id = ?", column.BoardID).Scan(&last).Error; err != nil {
+			return err
+		}
+		column.Position = 0
+		if last.Max != nil {
+			column.Position = *last.Max + 1
+		}
+
+		return tx.Create(column).Error
+	})
+}
+
+// UpdateColumn saves a column. When its Done flag changes, the completion
+// of the tasks it holds is updated to match.
+func (s *GormBoardStore) UpdateColumn(ctx context.Context, column *Column) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(column).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Task{}).Where("column_id = ? AND completed <> ?", column.ID, column.Done).
+			Updates(map[string]any{"completed": column.Done, "version": nextVersion}).Error
+	})
+}
+
+// DeleteColumn removes a column that holds no tasks.
+func (s *GormBoardStore) DeleteColumn(ctx context.Context, boardID, columnID uint) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var column Column
+		if err := tx.Where("board_id = ?", boardID).First(&column, columnID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrColumnNotFound
+			}
+			return err
+		}
+
+		var tasks int64
+		if err := tx.Model(&Task{}).Where("column_id = ?", column.ID).Count(&tasks).Error; err != nil {
+			return err
+		}
+		if tasks > 0 {
+			return ErrColumnNotEmpty
+		}
+
+		return tx.Delete(&column).Error
+	})
+}
+
+// moveTaskLockKey is the PostgreSQL advisory lock taken by lockTaskPositions.
+const moveTaskLockKey = 0x7461736b6d6f7665 // "taskmove"
+
+// lockTaskPositions serializes the transactions of tx that renumber task
+// positions, such as MoveTask and TaskStore.Restore, until it ends.
+func lockTaskPositions(tx *gorm.DB) error {
+	if tx.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", moveTaskLockKey).Error
+}
+
+// MoveTask relocates a task inside a single transaction. Moves are
+// serialized so that concurrent reorders never leave duplicate positions
+// in a column; SQLite already serializes writers.
+func (s *GormBoardStore) MoveTask(ctx context.Context, taskID, columnID uint, position int) (*Task, error) {
+	var task *Task
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockTaskPositions(tx); err != nil {
+			return err
+		}
+
+		var err error
+		if task, err = findTask(tx, taskID); err != nil {
+			return err
+		}
+
+		var column Column
+		if err := tx.First(&column, columnID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrColumnNotFound
+			}
+			return err
+		}
+
+		// Close the gap left in the source column.
+		if task.ColumnID != nil {
+			err := tx.Model(&Task{}).
+				Where("column_id = ? AND position > ? AND id <> ?", *task.ColumnID, task.Position, task.ID).
+				Updates(map[string]any{"position": gorm.Expr("position - 1"), "version": nextVersion}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var size int64
+		if err := tx.Model(&Task{}).Where("column_id = ? AND id <> ?", column.ID, task.ID).Count(&size).Error; err != nil {
+			return err
+		}
+		position = max(0, min(position, int(size)))
+
+		// Open a slot in the target column.
+		err = tx.Model(&Task{}).
+			Where("column_id = ? AND position >= ? AND id <> ?", column.ID, position, task.ID).
+			Updates(map[string]any{"position": gorm.Expr("position + 1"), "version": nextVersion}).Error
+		if err != nil {
+			return err
+		}
+
+		task.BoardID = &column.BoardID
+		task.ColumnID = &column.ID
+		task.Position = position
+		task.Completed = column.Done
+		task.Version++
+		return tx.Omit(clause.Associations).Save(task).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,439 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultColumns are created for a new board when none are given.
+var defaultColumns = []Column{
+	{Name: "To do"},
+	{Name: "In progress"},
+	{Name: "Done", Done: true},
+}
+
+// writeBoardError maps BoardStore errors to HTTP responses.
+func writeBoardError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrBoardNotFound):
+		writeError(c, http.StatusNotFound, "board not found")
+	case errors.Is(err, ErrColumnNotFound):
+		writeError(c, http.StatusNotFound, "column not found")
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+	case errors.Is(err, ErrColumnNotEmpty):
+		writeError(c, http.StatusConflict, "column still contains tasks")
+	default:
+		writeInternalError(c, err, fallback)
+	}
+}
+
+// listBoards returns all boards without their columns.
+func (s *Server) listBoards(c *gin.Context) {
+	var boards []Board
+	err := TrackDBOperation(c.Request.Context(), "query_all_boards", func(ctx context.Context) error {
+		var err error
+		boards, err = s.boards.ListBoards(ctx)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch boards")
+		return
+	}
+
+	c.JSON(http.StatusOK, boards)
+}
+
+// ColumnInput represents the payload for creating a column.
+type ColumnInput struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+	Done bool   `json:"done"`
+}
+
+// CreateBoardInput represents the expected payload for creating a board.
+// When Columns is omitted the board starts with To do, In progress and
+// Done columns.
+type CreateBoardInput struct {
+	Name    string        `json:"name" binding:"required,min=1,max=100"`
+	Columns []ColumnInput `json:"columns" binding:"omitempty,dive"`
+}
+
+// createBoard creates a board and its initial columns. The authenticated
+// user, who must be an editor in the workspace, becomes its owner.
+func (s *Server) createBoard(c *gin.Context) {
+	if !s.authorizeWorkspace(c, RoleEditor) {
+		return
+	}
+
+	var input CreateBoardInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	board := Board{Name: input.Name}
+	if input.Columns == nil {
+		board.Columns = append([]Column(nil), defaultColumns...)
+	}
+	for _, column := range input.Columns {
+		board.Columns = append(board.Columns, Column{Name: column.Name, Done: column.Done})
+	}
+	for i := range board.Columns {
+		board.Columns[i].Position = i
+	}
+	if userID, ok := currentUserID(c); ok && s.members != nil {
+		board.Members = []BoardMember{{UserID: userID, Role: RoleOwner}}
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "create_board", func(ctx context.Context) error {
+		return s.boards.CreateBoard(ctx, &board)
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to create board")
+		return
+	}
+
+	c.JSON(http.StatusCreated, board)
+}
+
+// getBoard returns a board with its columns.
+func (s *Server) getBoard(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var board *Board
+	err := TrackDBOperation(c.Request.Context(), "find_board", func(ctx context.Context) error {
+		var err error
+		board, err = s.boards.GetBoard(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to fetch board")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// UpdateBoardInput represents the fields that can be updated in a board.
+type UpdateBoardInput struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=100"`
+}
+
+// updateBoard renames a board.
+func (s *Server) updateBoard(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input UpdateBoardInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var board *Board
+	err := TrackDBOperation(c.Request.Context(), "update_board", func(ctx context.Context) error {
+		var err error
+		if board, err = s.boards.GetBoard(ctx, id); err != nil {
+			return err
+		}
+		if input.Name != nil {
+			board.Name = *input.Name
+		}
+		return s.boards.UpdateBoard(ctx, board)
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to update board")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// deleteBoard deletes a board and its columns, keeping its tasks.
+func (s *Server) deleteBoard(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_board", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			// The tasks that DeleteBoard detaches. Tasks in the trash are
+			// detached too, but nobody follows them until they are restored.
+			detached, err := s.tasks.List(ctx, TaskFilter{BoardID: &id}, ListOptions{})
+			if err != nil {
+				return err
+			}
+			if err := s.boards.DeleteBoard(ctx, id); err != nil {
+				return err
+			}
+			for i := range detached {
+				task, err := s.tasks.Get(ctx, detached[i].ID)
+				if err != nil {
+					return err
+				}
+				if err := s.recordTaskEvent(ctx, TaskUpdated, &detached[i], task); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to delete board")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listColumns returns the columns of a board in display order.
+func (s *Server) listColumns(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var columns []Column
+	err := TrackDBOperation(c.Request.Context(), "query_columns", func(ctx context.Context) error {
+		var err error
+		columns, err = s.boards.ListColumns(ctx, boardID)
+		return err
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to fetch columns")
+		return
+	}
+
+	c.JSON(http.StatusOK, columns)
+}
+
+// createColumn appends a column to a board.
+func (s *Server) createColumn(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input ColumnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	column := Column{BoardID: boardID, Name: input.Name, Done: input.Done}
+	err := TrackDBOperation(c.Request.Context(), "create_column", func(ctx context.Context) error {
+		return s.boards.CreateColumn(ctx, &column)
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to create column")
+		return
+	}
+
+	c.JSON(http.StatusCreated, column)
+}
+
+// UpdateColumnInput represents the fields that can be updated in a column.
+type UpdateColumnInput struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position" binding:"omitempty,min=0"`
+}
+
+// updateColumn renames, reorders or flags a column as done.
+func (s *Server) updateColumn(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	columnID, ok := parseIDParam(c, "column_id")
+	if !ok {
+		return
+	}
+
+	var input UpdateColumnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var column *Column
+	err := TrackDBOperation(c.Request.Context(), "update_column", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			if column, err = s.boards.GetColumn(ctx, boardID, columnID); err != nil {
+				return err
+			}
+			if input.Name != nil {
+				column.Name = *input.Name
+			}
+			if input.Done != nil {
+				column.Done = *input.Done
+			}
+			if input.Position != nil {
+				column.Position = *input.Position
+			}
+
+			// The tasks whose completion UpdateColumn changes to match.
+			stale := !column.Done
+			changed, err := s.tasks.List(ctx, TaskFilter{ColumnID: &column.ID, Completed: &stale}, ListOptions{})
+			if err != nil {
+				return err
+			}
+			if err := s.boards.UpdateColumn(ctx, column); err != nil {
+				return err
+			}
+			for i := range changed {
+				task, err := s.tasks.Get(ctx, changed[i].ID)
+				if err != nil {
+					return err
+				}
+				if err := s.recordTaskEvent(ctx, TaskUpdated, &changed[i], task); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to update column")
+		return
+	}
+
+	// Changing the done flag may have changed task completion
+	s.refreshTaskMetrics(c)
+
+	c.JSON(http.StatusOK, column)
+}
+
+// deleteColumn deletes an empty column.
+func (s *Server) deleteColumn(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	columnID, ok := parseIDParam(c, "column_id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_column", func(ctx context.Context) error {
+		return s.boards.DeleteColumn(ctx, boardID, columnID)
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to delete column")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MoveTaskInput represents the target of a task move. Position is the
+// zero-based index in the column; it defaults to the top of the column.
+type MoveTaskInput struct {
+	ColumnID uint `json:"column_id" binding:"required"`
+	Position int  `json:"position" binding:"min=0"`
+}
+
+// moveTask moves a task to a position in a column, possibly on another board.
+func (s *Server) moveTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input MoveTaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	// The route checks the role on the source board; check the target.
+	if !s.authorizeColumn(c, input.ColumnID, RoleEditor) {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "move_task", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			task, err = s.moveTaskTo(ctx, id, input.ColumnID, input.Position)
+			return err
+		})
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to move task")
+		return
+	}
+
+	// Update metrics since the target column may change completion
+	s.refreshTaskMetrics(c)
+
+	c.JSON(http.StatusOK, task)
+}
+
+// moveTaskTo moves a task to a position of a column and records the move.
+func (s *Server) moveTaskTo(ctx context.Context, id, columnID uint, position int) (*Task, error) {
+	before, err := s.tasks.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.boards.MoveTask(ctx, id, columnID, position)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordTaskEvent(ctx, TaskUpdated, before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// syncCompletedColumn keeps a board task's column consistent with its
+// Completed flag after a client toggles it directly: completing a task
+// moves it to the end of the board's first done column, and reopening it
+// moves it to the end of the first column that is not done. Tasks that
+// are not on a board, or boards without a matching column, are left as is.
+func (s *Server) syncCompletedColumn(ctx context.Context, task *Task) (*Task, error) {
+	if s.boards == nil || task.BoardID == nil {
+		return task, nil
+	}
+
+	columns, err := s.boards.ListColumns(ctx, *task.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *Column
+	for i := range columns {
+		if task.ColumnID != nil && columns[i].ID == *task.ColumnID && columns[i].Done == task.Completed {
+			return task, nil
+		}
+		if target == nil && columns[i].Done == task.Completed {
+			target = &columns[i]
+		}
+	}
+	if target == nil {
+		return task, nil
+	}
+
+	return s.boards.MoveTask(ctx, task.ID, target.ID, math.MaxInt)
+}
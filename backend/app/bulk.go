@@ -0,0 +1,259 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Modes of a bulk request.
+const (
+	// BulkAtomic applies every operation or, when one fails, none.
+	BulkAtomic = "atomic"
+	// BulkBestEffort applies the operations that succeed and skips the
+	// others.
+	BulkBestEffort = "best_effort"
+)
+
+// Operations of a bulk request.
+const (
+	BulkCreate   = "create"
+	BulkUpdate   = "update"
+	BulkComplete = "complete"
+	BulkDelete   = "delete"
+	BulkMove     = "move"
+)
+
+// BulkOperation is one operation of a bulk request. ID names the task of
+// every operation but create.
+type BulkOperation struct {
+	Op string `json:"op" binding:"required,oneof=create update complete delete move"`
+	ID uint   `json:"id" binding:"required_unless=Op create"`
+	// Task is the task to create, as taken by POST /tasks.
+	Task *CreateTaskInput `json:"task" binding:"required_if=Op create"`
+	// Patch is the merge patch of an update, as taken by PATCH /tasks/:id.
+	Patch json.RawMessage `json:"patch" binding:"required_if=Op update"`
+	// Completed is the completion set by complete; it defaults to true.
+	Completed *bool `json:"completed"`
+	// ColumnID and Position are the target of a move, as taken by
+	// POST /tasks/:id/move.
+	ColumnID uint `json:"column_id" binding:"required_if=Op move"`
+	Position int  `json:"position" binding:"min=0"`
+}
+
+// BulkInput is the payload of a bulk request. Mode defaults to BulkAtomic.
+type BulkInput struct {
+	Mode       string          `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BulkOperation `json:"operations" binding:"required,min=1,max=100"`
+}
+
+// BulkResult is the outcome of one operation, with the status and task
+// its single-task route would have responded with.
+type BulkResult struct {
+	Index  int      `json:"index"`
+	Op     string   `json:"op"`
+	Status int      `json:"status"`
+	Task   *Task    `json:"task,omitempty"`
+	Error  *Problem `json:"error,omitempty"`
+}
+
+// BulkResponse reports whether a bulk request was committed and the
+// outcome of each of its operations, in request order.
+type BulkResponse struct {
+	Committed bool         `json:"committed"`
+	Results   []BulkResult `json:"results"`
+}
+
+// errBulkRollback rolls back an atomic bulk request after an operation failed.
+var errBulkRollback = errors.New("bulk operation failed")
+
+// bulkTasks applies a list of task operations in one transaction. In
+// atomic mode the first failure rolls the whole batch back, and the
+// response takes the status of the failed operation; the other operations
+// are reported with 424 Failed Dependency. In best-effort mode every
+// operation runs in its own savepoint and the batch is committed with
+// whichever operations succeeded.
+func (s *Server) bulkTasks(c *gin.Context) {
+	var input BulkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+	if input.Mode == "" {
+		input.Mode = BulkAtomic
+	}
+
+	var user *uint
+	if userID, ok := currentUserID(c); ok {
+		user = &userID
+	}
+
+	results := make([]BulkResult, len(input.Operations))
+	for i, op := range input.Operations {
+		results[i] = BulkResult{Index: i, Op: op.Op}
+	}
+	failed := -1
+	record := func(i, status int, task *Task, err error) {
+		if err != nil {
+			problem := s.bulkProblem(c, err)
+			results[i].Status, results[i].Error = problem.Status, problem
+			return
+		}
+		results[i].Status, results[i].Task = status, task
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "bulk_tasks", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			for i := range input.Operations {
+				op := &input.Operations[i]
+				if input.Mode == BulkAtomic {
+					status, task, err := s.runBulkOperation(ctx, user, op)
+					record(i, status, task, err)
+					if err != nil {
+						failed = i
+						return errBulkRollback
+					}
+					continue
+				}
+
+				var status int
+				var task *Task
+				err := s.inTx(ctx, func(ctx context.Context) error {
+					var err error
+					status, task, err = s.runBulkOperation(ctx, user, op)
+					return err
+				})
+				record(i, status, task, err)
+			}
+			return nil
+		})
+	}, attribute.Int("batch.size", len(input.Operations)), attribute.String("batch.mode", input.Mode))
+
+	if err != nil && !errors.Is(err, errBulkRollback) {
+		writeInternalError(c, err, "failed to apply operations")
+		return
+	}
+
+	if failed >= 0 {
+		for i := range results {
+			if i != failed {
+				results[i].Task = nil
+				results[i].Error = newProblem(http.StatusFailedDependency, fmt.Sprintf("rolled back because operation %d failed", failed))
+				results[i].Status = http.StatusFailedDependency
+			}
+		}
+		c.JSON(results[failed].Status, BulkResponse{Committed: false, Results: results})
+		return
+	}
+
+	// Update metrics after the batch was committed
+	s.refreshTaskMetrics(c)
+
+	c.JSON(http.StatusOK, BulkResponse{Committed: true, Results: results})
+}
+
+// runBulkOperation validates and applies one operation of a bulk request
+// on behalf of a user, or anonymously when userID is nil, returning the
+// status and task its single-task route would have responded with.
+func (s *Server) runBulkOperation(ctx context.Context, userID *uint, op *BulkOperation) (int, *Task, error) {
+	if err := binding.Validator.ValidateStruct(op); err != nil {
+		return 0, nil, inputProblem(err)
+	}
+	if s.boards == nil && (op.Op == BulkMove || op.Op == BulkCreate && op.Task.ColumnID != nil) {
+		return 0, nil, newProblem(http.StatusBadRequest, "boards are not supported")
+	}
+
+	// authorize checks the role of the user on a board, or in the workspace
+	// for tasks off boards, like the single-task routes.
+	authorize := func(boardID *uint) error {
+		if userID == nil {
+			return nil
+		}
+		return s.checkTaskRole(ctx, *userID, boardID, RoleEditor)
+	}
+	authorizeColumn := func(columnID uint) error {
+		column, err := s.boards.FindColumn(ctx, columnID)
+		if err != nil {
+			return err
+		}
+		return authorize(&column.BoardID)
+	}
+
+	if op.Op == BulkCreate {
+		err := authorize(nil)
+		if op.Task.ColumnID != nil {
+			err = authorizeColumn(*op.Task.ColumnID)
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		task, err := s.insertTask(ctx, op.Task)
+		return http.StatusCreated, task, err
+	}
+
+	task, err := s.tasks.Get(ctx, op.ID)
+	if errors.Is(err, ErrTaskNotFound) && op.Op == BulkDelete {
+		// Deleting a missing task succeeds, as with DELETE /tasks/:id.
+		return http.StatusNoContent, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := authorize(task.BoardID); err != nil {
+		return 0, nil, err
+	}
+
+	switch op.Op {
+	case BulkUpdate:
+		input, err := applyMergePatch(updateInputOf(task), op.Patch)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&input)
+		}
+		if err != nil {
+			return 0, nil, inputProblem(err)
+		}
+		task, err = s.replaceTask(ctx, task, &input)
+		return http.StatusOK, task, err
+
+	case BulkComplete:
+		input := updateInputOf(task)
+		input.Completed = op.Completed == nil || *op.Completed
+		task, err = s.replaceTask(ctx, task, &input)
+		return http.StatusOK, task, err
+
+	case BulkDelete:
+		return http.StatusNoContent, nil, s.trashTask(ctx, task)
+
+	default: // BulkMove
+		if err := authorizeColumn(op.ColumnID); err != nil {
+			return 0, nil, err
+		}
+		task, err = s.moveTaskTo(ctx, op.ID, op.ColumnID, op.Position)
+		return http.StatusOK, task, err
+	}
+}
+
+// bulkProblem maps the error of a bulk operation to the Problem its
+// single-task route would have responded with.
+func (s *Server) bulkProblem(c *gin.Context, err error) *Problem {
+	var problem *Problem
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.Is(err, ErrTaskNotFound):
+		return newProblem(http.StatusNotFound, "task not found")
+	case errors.Is(err, ErrColumnNotFound):
+		return newProblem(http.StatusNotFound, "column not found")
+	case errors.Is(err, ErrVersionConflict):
+		return newProblem(http.StatusConflict, err.Error())
+	default:
+		_ = c.Error(err)
+		return newProblem(http.StatusInternalServerError, "failed to apply operation")
+	}
+}
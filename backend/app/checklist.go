@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeChecklistError maps ChecklistStore errors to HTTP responses.
+func writeChecklistError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+	case errors.Is(err, ErrChecklistItemNotFound):
+		writeError(c, http.StatusNotFound, "checklist item not found")
+	default:
+		writeInternalError(c, err, fallback)
+	}
+}
+
+// autoCompleteTask completes a task flagged with AutoComplete once its
+// whole checklist is done, moving it to a done column when it is on a board.
+func (s *Server) autoCompleteTask(ctx context.Context, taskID uint) error {
+	task, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if !task.AutoComplete || task.Completed || !task.Progress.complete() {
+		return nil
+	}
+
+	before := *task
+	task.Completed = true
+	if err := s.tasks.Update(ctx, task); err != nil {
+		return err
+	}
+	if task, err = s.syncCompletedColumn(ctx, task); err != nil {
+		return err
+	}
+	return s.recordTaskEvent(ctx, TaskUpdated, &before, task)
+}
+
+// listChecklistItems returns the checklist of a task in display order.
+func (s *Server) listChecklistItems(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var items []ChecklistItem
+	err := TrackDBOperation(c.Request.Context(), "query_checklist_items", func(ctx context.Context) error {
+		var err error
+		items, err = s.checklists.ListItems(ctx, taskID)
+		return err
+	})
+
+	if err != nil {
+		writeChecklistError(c, err, "failed to fetch checklist")
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// ChecklistItemInput represents the payload for adding a checklist item.
+type ChecklistItemInput struct {
+	Title string `json:"title" binding:"required,min=1,max=200"`
+	Done  bool   `json:"done"`
+}
+
+// createChecklistItem appends an item to a task's checklist.
+func (s *Server) createChecklistItem(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input ChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	item := ChecklistItem{TaskID: taskID, Title: input.Title, Done: input.Done}
+	err := TrackDBOperation(c.Request.Context(), "create_checklist_item", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			if err := s.checklists.CreateItem(ctx, &item); err != nil {
+				return err
+			}
+			return s.autoCompleteTask(ctx, taskID)
+		})
+	})
+
+	if err != nil {
+		writeChecklistError(c, err, "failed to create checklist item")
+		return
+	}
+
+	s.refreshTaskMetrics(c)
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateChecklistItemInput represents the fields that can be updated in a
+// checklist item. Position is the zero-based index in the checklist.
+type UpdateChecklistItemInput struct {
+	Title    *string `json:"title" binding:"omitempty,min=1,max=200"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position" binding:"omitempty,min=0"`
+}
+
+// updateChecklistItem renames, toggles or reorders a checklist item.
+func (s *Server) updateChecklistItem(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "item_id")
+	if !ok {
+		return
+	}
+
+	var input UpdateChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var item *ChecklistItem
+	err := TrackDBOperation(c.Request.Context(), "update_checklist_item", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			if item, err = s.checklists.GetItem(ctx, taskID, itemID); err != nil {
+				return err
+			}
+			if input.Title != nil {
+				item.Title = *input.Title
+			}
+			if input.Done != nil {
+				item.Done = *input.Done
+			}
+			if input.Position != nil {
+				item.Position = *input.Position
+			}
+			if err := s.checklists.UpdateItem(ctx, item); err != nil {
+				return err
+			}
+			return s.autoCompleteTask(ctx, taskID)
+		})
+	})
+
+	if err != nil {
+		writeChecklistError(c, err, "failed to update checklist item")
+		return
+	}
+
+	// Checking off the last item may have completed the task
+	s.refreshTaskMetrics(c)
+
+	c.JSON(http.StatusOK, item)
+}
+
+// deleteChecklistItem removes an item from a task's checklist.
+func (s *Server) deleteChecklistItem(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "item_id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_checklist_item", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			if err := s.checklists.DeleteItem(ctx, taskID, itemID); err != nil {
+				return err
+			}
+			return s.autoCompleteTask(ctx, taskID)
+		})
+	})
+
+	if err != nil {
+		writeChecklistError(c, err, "failed to delete checklist item")
+		return
+	}
+
+	s.refreshTaskMetrics(c)
+
+	c.Status(http.StatusNoContent)
+}
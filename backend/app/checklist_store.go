@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrChecklistItemNotFound is returned when no checklist item of the task
+// matches the requested ID.
+var ErrChecklistItemNotFound = errors.New("checklist item not found")
+
+// ChecklistStore persists the checklist items of tasks. It shares the
+// tasks table with the TaskStore of the same backend. Item positions are
+// kept contiguous, starting at zero.
+type ChecklistStore interface {
+	// ListItems returns the checklist of a task in display order.
+	ListItems(ctx context.Context, taskID uint) ([]ChecklistItem, error)
+	GetItem(ctx context.Context, taskID, itemID uint) (*ChecklistItem, error)
+	// CreateItem adds an item to the end of its task's checklist.
+	CreateItem(ctx context.Context, item *ChecklistItem) error
+	// UpdateItem saves an item, shifting its siblings when its position
+	// changed. Positions past the end move the item last.
+	UpdateItem(ctx context.Context, item *ChecklistItem) error
+	DeleteItem(ctx context.Context, taskID, itemID uint) error
+}
+
+// GormChecklistStore is a ChecklistStore backed by a GORM database connection.
+type GormChecklistStore struct {
+	db *gorm.DB
+}
+
+// NewGormChecklistStore returns a ChecklistStore that persists checklist
+// items through db.
+func NewGormChecklistStore(db *gorm.DB) *GormChecklistStore {
+	return &GormChecklistStore{db: db}
+}
+
+// taskExists fails with ErrTaskNotFound unless the task exists.
+func taskExists(db *gorm.DB, id uint) error {
+	var count int64
+	if err := db.Model(&Task{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// findChecklistItem loads an item of the given task through db, which may
+// be a transaction.
+func findChecklistItem(db *gorm.DB, taskID, itemID uint) (*ChecklistItem, error) {
+	var item ChecklistItem
+	err := db.Where("task_id = ?", taskID).First(&item, itemID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChecklistItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListItems returns the checklist of a task ordered by position.
+func (s *GormChecklistStore) ListItems(ctx context.Context, taskID uint) ([]ChecklistItem, error) {
+	db := dbFor(ctx, s.db)
+	if err := taskExists(db, taskID); err != nil {
+		return nil, err
+	}
+
+	items := []ChecklistItem{}
+	err := db.Where("task_id = ?", taskID).Order("position asc, id asc").Find(&items).Error
+	return items, err
+}
+
+// GetItem returns a checklist item of the given task.
+func (s *GormChecklistStore) GetItem(ctx context.Context, taskID, itemID uint) (*ChecklistItem, error) {
+	return findChecklistItem(dbFor(ctx, s.db), taskID, itemID)
+}
+
+// CreateItem appends an item to its task's checklist.
+func (s *GormChecklistStore) CreateItem(ctx context.Context, item *ChecklistItem) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := taskExists(tx, item.TaskID); err != nil {
+			return err
+		}
+
+		var size int64
+		if err := tx.Model(&ChecklistItem{}).Where("task_id = ?", item.TaskID).Count(&size).Error; err != nil {
+			return err
+		}
+		item.Position = int(size)
+
+		return tx.Create(item).Error
+	})
+}
+
+// UpdateItem saves an item, moving it within its checklist when its
+// position changed.
+func (s *GormChecklistStore) UpdateItem(ctx context.Context, item *ChecklistItem) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		current, err := findChecklistItem(tx, item.TaskID, item.ID)
+		if err != nil {
+			return err
+		}
+
+		if item.Position != current.Position {
+			var size int64
+			if err := tx.Model(&ChecklistItem{}).Where("task_id = ?", item.TaskID).Count(&size).Error; err != nil {
+				return err
+			}
+			item.Position = max(0, min(item.Position, int(size)-1))
+
+			siblings := tx.Model(&ChecklistItem{}).Where("task_id = ? AND id <> ?", item.TaskID, item.ID)
+			if item.Position < current.Position {
+				err = siblings.Where("position >= ? AND position < ?", item.Position, current.Position).
+					Update("position", gorm.Expr("position + 1")).Error
+			} else {
+				err = siblings.Where("position > ? AND position <= ?", current.Position, item.Position).
+					Update("position", gorm.Expr("position - 1")).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(item).Error
+	})
+}
+
+// DeleteItem removes an item and closes the gap it leaves.
+func (s *GormChecklistStore) DeleteItem(ctx context.Context, taskID, itemID uint) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		item, err := findChecklistItem(tx, taskID, itemID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(item).Error; err != nil {
+			return err
+		}
+		return tx.Model(&ChecklistItem{}).Where("task_id = ? AND position > ?", taskID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
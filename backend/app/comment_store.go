@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrCommentNotFound is returned when no comment of the task matches the
+// requested ID.
+var ErrCommentNotFound = errors.New("comment not found")
+
+// CommentStore persists the comment threads of tasks. It shares the tasks
+// table with the TaskStore of the same backend; comments are deleted
+// together with their task.
+type CommentStore interface {
+	// ListComments returns the comments of a task, oldest first.
+	ListComments(ctx context.Context, taskID uint) ([]Comment, error)
+	GetComment(ctx context.Context, taskID, commentID uint) (*Comment, error)
+	CreateComment(ctx context.Context, comment *Comment) error
+	UpdateComment(ctx context.Context, comment *Comment) error
+	DeleteComment(ctx context.Context, taskID, commentID uint) error
+}
+
+// GormCommentStore is a CommentStore backed by a GORM database connection.
+type GormCommentStore struct {
+	db *gorm.DB
+}
+
+// NewGormCommentStore returns a CommentStore that persists comments through db.
+func NewGormCommentStore(db *gorm.DB) *GormCommentStore {
+	return &GormCommentStore{db: db}
+}
+
+// ListComments returns the comments of a task in the order they were posted.
+func (s *GormCommentStore) ListComments(ctx context.Context, taskID uint) ([]Comment, error) {
+	db := dbFor(ctx, s.db)
+	if err := taskExists(db, taskID); err != nil {
+		return nil, err
+	}
+
+	comments := []Comment{}
+	err := db.Where("task_id = ?", taskID).Order("created_at asc, id asc").Find(&comments).Error
+	return comments, err
+}
+
+// GetComment returns a comment of the given task.
+func (s *GormCommentStore) GetComment(ctx context.Context, taskID, commentID uint) (*Comment, error) {
+	var comment Comment
+	err := dbFor(ctx, s.db).Where("task_id = ?", taskID).First(&comment, commentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// CreateComment adds a comment to the thread of its task.
+func (s *GormCommentStore) CreateComment(ctx context.Context, comment *Comment) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := taskExists(tx, comment.TaskID); err != nil {
+			return err
+		}
+		return tx.Create(comment).Error
+	})
+}
+
+// UpdateComment saves all fields of an existing comment.
+func (s *GormCommentStore) UpdateComment(ctx context.Context, comment *Comment) error {
+	return dbFor(ctx, s.db).Save(comment).Error
+}
+
+// DeleteComment removes a comment of the given task.
+func (s *GormCommentStore) DeleteComment(ctx context.Context, taskID, commentID uint) error {
+	res := dbFor(ctx, s.db).Where("task_id = ?", taskID).Delete(&Comment{}, commentID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
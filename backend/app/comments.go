@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errNotCommentAuthor is returned when a user edits or deletes a comment
+// posted by someone else.
+var errNotCommentAuthor = errors.New("not the comment author")
+
+// checkCommentAuthor allows authenticated users to change only their own
+// comments. Anonymous comments and anonymous requests are not restricted.
+func checkCommentAuthor(c *gin.Context, comment *Comment) error {
+	userID, ok := currentUserID(c)
+	if ok && comment.AuthorID != nil && *comment.AuthorID != userID {
+		return errNotCommentAuthor
+	}
+	return nil
+}
+
+// writeCommentError maps CommentStore errors to HTTP responses.
+func writeCommentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errNotCommentAuthor):
+		writeError(c, http.StatusForbidden, "only the author can change this comment")
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+	case errors.Is(err, ErrCommentNotFound):
+		writeError(c, http.StatusNotFound, "comment not found")
+	default:
+		writeInternalError(c, err, fallback)
+	}
+}
+
+// listComments returns the comment thread of a task, oldest first.
+func (s *Server) listComments(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var comments []Comment
+	err := TrackDBOperation(c.Request.Context(), "query_comments", func(ctx context.Context) error {
+		var err error
+		comments, err = s.comments.ListComments(ctx, taskID)
+		return err
+	})
+
+	if err != nil {
+		writeCommentError(c, err, "failed to fetch comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateCommentInput represents the expected payload for posting a comment.
+// Author is required for anonymous requests and ignored for authenticated
+// ones, which are attributed to their user.
+type CreateCommentInput struct {
+	Author string `json:"author" binding:"max=100"`
+	Body   string `json:"body" binding:"required,min=1,max=10000"`
+}
+
+// createComment posts a comment on a task.
+func (s *Server) createComment(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input CreateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	comment := Comment{TaskID: taskID, Author: input.Author, Body: input.Body}
+	if identity, ok := currentIdentity(c); ok {
+		comment.AuthorID, comment.Author = &identity.UserID, identity.Name
+	}
+	if comment.Author == "" {
+		writeError(c, http.StatusBadRequest, "author is required")
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "create_comment", func(ctx context.Context) error {
+		return s.comments.CreateComment(ctx, &comment)
+	})
+
+	if err != nil {
+		writeCommentError(c, err, "failed to create comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateCommentInput represents the new body of an edited comment.
+type UpdateCommentInput struct {
+	Body string `json:"body" binding:"required,min=1,max=10000"`
+}
+
+// updateComment edits the body of a comment and records when it happened.
+func (s *Server) updateComment(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "comment_id")
+	if !ok {
+		return
+	}
+
+	var input UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var comment *Comment
+	err := TrackDBOperation(c.Request.Context(), "update_comment", func(ctx context.Context) error {
+		var err error
+		if comment, err = s.comments.GetComment(ctx, taskID, commentID); err != nil {
+			return err
+		}
+		if err := checkCommentAuthor(c, comment); err != nil {
+			return err
+		}
+		if comment.Body == input.Body {
+			return nil
+		}
+		now := time.Now()
+		comment.Body = input.Body
+		comment.EditedAt = &now
+		return s.comments.UpdateComment(ctx, comment)
+	})
+
+	if err != nil {
+		writeCommentError(c, err, "failed to update comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// deleteComment removes a comment from a task's thread.
+func (s *Server) deleteComment(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "comment_id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_comment", func(ctx context.Context) error {
+		comment, err := s.comments.GetComment(ctx, taskID, commentID)
+		if err != nil {
+			return err
+		}
+		if err := checkCommentAuthor(c, comment); err != nil {
+			return err
+		}
+		return s.comments.DeleteComment(ctx, taskID, commentID)
+	})
+
+	if err != nil {
+		writeCommentError(c, err, "failed to delete comment")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,233 @@
+// Package app contains the backend server logic for TaskBoard, including
+// database initialization, environment variable handling, and application startup.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Supported values for the DB_DRIVER setting.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DBConfig describes which database to connect to and how.
+type DBConfig struct {
+	Driver   string // DriverPostgres or DriverSQLite
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	Path     string // SQLite database file, or ":memory:"
+}
+
+// LoadDBConfig reads the database settings from environment variables.
+func LoadDBConfig() DBConfig {
+	return DBConfig{
+		Driver:   getEnv("DB_DRIVER", DriverPostgres),
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		Name:     getEnv("DB_NAME", "taskboard"),
+		Path:     getEnv("DB_PATH", "taskboard.db"),
+	}
+}
+
+// dialector returns the GORM dialector for the configured driver.
+func (cfg DBConfig) dialector() (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverPostgres:
+		dsn := fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name,
+		)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		// Foreign keys are off by default in SQLite; the busy timeout makes
+		// concurrent writers wait instead of failing with SQLITE_BUSY.
+		dsn := cfg.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q (expected %q or %q)", cfg.Driver, DriverPostgres, DriverSQLite)
+	}
+}
+
+// OpenDB connects to the database described by cfg, adds OpenTelemetry
+// instrumentation and runs automatic migrations for all database models.
+func OpenDB(cfg DBConfig, gormConfig *gorm.Config) (*gorm.DB, error) {
+	dialector, err := cfg.dialector()
+	if err != nil {
+		return nil, err
+	}
+
+	// Stores rely on driver errors being translated to gorm.ErrDuplicatedKey
+	// and friends.
+	if gormConfig == nil {
+		gormConfig = &gorm.Config{}
+	}
+	gormConfig.TranslateError = true
+
+	db, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+
+	// Configure connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get database connection: %w", err)
+	}
+
+	if cfg.Driver == DriverSQLite {
+		// SQLite allows a single writer, and every connection to ":memory:"
+		// opens a separate database, so share one long-lived connection.
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	} else {
+		sqlDB.SetMaxIdleConns(5)
+		sqlDB.SetMaxOpenConns(20)
+		sqlDB.SetConnMaxLifetime(time.Hour)
+	}
+
+	if err := db.AutoMigrate(&Workspace{}, &User{}, &Task{}, &Board{}, &Column{}, &Label{}, &ChecklistItem{}, &Comment{}, &APIKey{}, &BoardMember{}, &TaskEvent{}, &OutboxEvent{}, &Webhook{}, &WebhookDelivery{}); err != nil {
+		return nil, fmt.Errorf("migrate database: %w", err)
+	}
+	if err := migrateWorkspaces(db); err != nil {
+		return nil, fmt.Errorf("migrate workspaces: %w", err)
+	}
+
+	if cfg.Driver == DriverPostgres {
+		// GIN index backing the full-text search in GormTaskStore.Search.
+		// It replaces the title-only idx_tasks_search index.
+		for _, stmt := range []string{
+			"DROP INDEX IF EXISTS idx_tasks_search",
+			"CREATE INDEX IF NOT EXISTS idx_tasks_fulltext ON tasks USING GIN (" + taskSearchVector + ")",
+		} {
+			if err := db.Exec(stmt).Error; err != nil {
+				return nil, fmt.Errorf("create search index: %w", err)
+			}
+		}
+	}
+
+	if err := db.Use(workspaceScope{}); err != nil {
+		return nil, err
+	}
+
+	// Add OpenTelemetry instrumentation to GORM
+	if err := db.Use(otelgorm.NewPlugin()); err != nil {
+		return nil, fmt.Errorf("add OTEL instrumentation to GORM: %w", err)
+	}
+
+	return db, nil
+}
+
+// migrateWorkspaces moves rows without a workspace, created before
+// workspaces existed or while authentication was disabled, to the default
+// workspace. The default workspace is the oldest one; OpenDB creates it
+// before the server can register any other.
+func migrateWorkspaces(db *gorm.DB) error {
+	// Label names and emails used to be unique across the whole database.
+	if db.Migrator().HasIndex(&Label{}, "idx_labels_name") {
+		if err := db.Migrator().DropIndex(&Label{}, "idx_labels_name"); err != nil {
+			return err
+		}
+	}
+	if db.Migrator().HasIndex(&User{}, "idx_users_email") {
+		if err := db.Migrator().DropIndex(&User{}, "idx_users_email"); err != nil {
+			return err
+		}
+	}
+
+	var workspace Workspace
+	res := db.Order("id asc").Limit(1).Find(&workspace)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		workspace.Name = "Default"
+		if err := db.Create(&workspace).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []any{&User{}, &Task{}, &Board{}, &Column{}, &Label{}, &ChecklistItem{}, &Comment{}, &BoardMember{}, &TaskEvent{}} {
+		err := db.Unscoped().Model(model).Where("workspace_id = ?", 0).UpdateColumn("workspace_id", workspace.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitDB opens the database selected by the DB_DRIVER environment variable
+// (PostgreSQL by default) and exits the process if it cannot be used.
+func InitDB() *gorm.DB {
+	cfg := LoadDBConfig()
+
+	// Create a custom logger for GORM that records metrics
+	customLogger := logger.New(
+		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
+		logger.Config{
+			SlowThreshold:             200 * time.Millisecond, // Slow SQL threshold
+			LogLevel:                  logger.Info,            // Log level
+			IgnoreRecordNotFoundError: false,                  // Log record not found error
+			Colorful:                  true,                   // Disable color
+		},
+	)
+
+	db, err := OpenDB(cfg, &gorm.Config{
+		Logger: customLogger,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("failed to get database connection: %v", err)
+	}
+
+	dbName, dbHost := cfg.Name, cfg.Host
+	if cfg.Driver == DriverSQLite {
+		dbName, dbHost = cfg.Path, ""
+	}
+
+	log.Printf("✅ Connected to %s and migrated", cfg.Driver)
+
+	// Record initial DB metrics
+	dbConnectionsOpen.Add(context.Background(), int64(sqlDB.Stats().OpenConnections),
+		metric.WithAttributes(
+			attribute.String("db_system", cfg.Driver),
+			attribute.String("db_name", dbName),
+			attribute.String("db_host", dbHost),
+		),
+	)
+
+	return db
+}
+
+// getEnv returns an environment variable value or a default
+// value if the variable is not set.
+func getEnv(key, def string) string {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return def
+	}
+	return val
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// debugMetrics records a single request metric to verify the export pipeline.
+func (s *Server) debugMetrics(c *gin.Context) {
+	requestCount.Add(c.Request.Context(), 1,
+		metric.WithAttributes(
+			attribute.String("method", "DEBUG"),
+			attribute.String("path", "/debug/metrics"),
+			attribute.Int("status", 200),
+		),
+	)
+
+	c.JSON(200, gin.H{
+		"message": "Debug metric recorded",
+		"info":    "Check Prometheus or the collector debug output",
+	})
+}
+
+// debugSlow simulates high latency.
+func (s *Server) debugSlow(c *gin.Context) {
+	// Sleep between 1-5 seconds
+	sleepTime := 1 + rand.Intn(4)
+	time.Sleep(time.Duration(sleepTime) * time.Second)
+
+	c.JSON(200, gin.H{
+		"message":       "Slow response simulated",
+		"sleep_seconds": sleepTime,
+	})
+}
+
+// debugStats reports runtime and task statistics.
+func (s *Server) debugStats(c *gin.Context) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	stats := gin.H{
+		"goroutines": runtime.NumGoroutine(),
+		"memory": gin.H{
+			"alloc_bytes":       memStats.Alloc,
+			"total_alloc_bytes": memStats.TotalAlloc,
+			"sys_bytes":         memStats.Sys,
+			"heap_objects":      memStats.HeapObjects,
+		},
+		"tasks": gin.H{
+			"count":     0,
+			"completed": 0,
+		},
+	}
+
+	// Get task counts
+	completed := true
+	totalCount, _ := s.tasks.Count(c.Request.Context(), TaskFilter{})
+	completedCount, _ := s.tasks.Count(c.Request.Context(), TaskFilter{Completed: &completed})
+
+	stats["tasks"].(gin.H)["count"] = totalCount
+	stats["tasks"].(gin.H)["completed"] = completedCount
+
+	c.JSON(200, stats)
+}
+
+// debugGenerateTasks generates random tasks for testing.
+func (s *Server) debugGenerateTasks(c *gin.Context) {
+	var count int = 10 // Default
+
+	// Parse count from query if provided
+	countParam := c.Query("count")
+	if countParam != "" {
+		parsedCount, err := strconv.Atoi(countParam)
+		if err == nil && parsedCount > 0 && parsedCount <= 100 {
+			count = parsedCount
+		}
+	}
+
+	priorities := []Priority{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+	// Generate tasks
+	for i := 0; i < count; i++ {
+		title := fmt.Sprintf("Generated Task #%d", i+1)
+		completed := rand.Intn(2) == 1 // 50% chance of being completed
+
+		task := Task{
+			Title:     title,
+			Priority:  priorities[rand.Intn(len(priorities))],
+			Completed: completed,
+		}
+
+		err := TrackDBOperation(c.Request.Context(), "create_task", func(ctx context.Context) error {
+			return s.tasks.Create(ctx, &task)
+		})
+
+		if err != nil {
+			writeInternalError(c, err, "failed to create tasks")
+			return
+		}
+	}
+
+	// Update metrics
+	UpdateTaskMetrics(c.Request.Context(), s.tasks)
+
+	c.JSON(200, gin.H{
+		"message": "Tasks generated successfully",
+		"count":   count,
+	})
+}
+
+// debugClearTasks moves all tasks to the trash.
+func (s *Server) debugClearTasks(c *gin.Context) {
+	err := TrackDBOperation(c.Request.Context(), "delete_all_tasks", func(ctx context.Context) error {
+		return s.tasks.DeleteAll(ctx)
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to clear tasks")
+		return
+	}
+
+	// Update metrics
+	UpdateTaskMetrics(c.Request.Context(), s.tasks)
+
+	c.JSON(200, gin.H{
+		"message": "All tasks moved to the trash",
+	})
+}
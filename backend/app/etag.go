@@ -0,0 +1,77 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskETag is the entity tag of a task: its quoted version.
+func taskETag(task *Task) string {
+	return `"` + strconv.FormatUint(uint64(task.Version), 10) + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header lists
+// etag or is "*". With weak set, tags are compared ignoring their W/
+// prefix, as If-None-Match requires.
+func etagMatches(header, etag string, weak bool) bool {
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch enforces the If-Match header of a request changing a task,
+// aborting with 412 when the task is missing or its version differs.
+// Requests without If-Match always pass.
+func checkIfMatch(c *gin.Context, task *Task) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+	if task == nil || !etagMatches(header, taskETag(task), false) {
+		writeError(c, http.StatusPreconditionFailed, ErrVersionConflict.Error())
+		return false
+	}
+	return true
+}
+
+// writeTask responds with a task and its ETag.
+func writeTask(c *gin.Context, status int, task *Task) {
+	c.Header("ETag", taskETag(task))
+	c.JSON(status, task)
+}
+
+// writeCachedJSON responds with v and a weak ETag derived from its
+// encoding, or with 304 Not Modified when the request's If-None-Match
+// already lists that ETag.
+func writeCachedJSON(c *gin.Context, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeInternalError(c, err, "failed to encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeat is how often an idle event stream sends a comment, keeping
+// proxies from closing the connection and letting the server notice
+// clients that went away.
+const sseHeartbeat = 15 * time.Second
+
+// sseRetry is the reconnection delay suggested to EventSource clients.
+const sseRetry = 3 * time.Second
+
+// writeSSE writes one Server-Sent Event. The JSON encoding of data never
+// spans lines, so it fits in a single data field.
+func writeSSE(w io.Writer, id, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return err
+}
+
+// streamEvents streams the task changes of the workspace as Server-Sent
+// Events: task.created, task.updated, task.restored and task.deleted, with
+// the task as data and the event's ID. A client reconnecting with the
+// Last-Event-ID header, or the last_event_id parameter, first receives
+// the events it missed; when those are no longer known, or the ID dates
+// from before the server started, it receives a reset event instead and
+// should reload its tasks.
+func (s *Server) streamEvents(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	if lastID != "" && !ValidEventID(lastID) {
+		writeError(c, http.StatusBadRequest, "invalid Last-Event-ID")
+		return
+	}
+
+	ctx := c.Request.Context()
+	sub, missed, resumed := s.hub.Subscribe(ctx, lastID)
+	defer s.hub.Unsubscribe(sub)
+	defer trackConnectedClient(ctx, "sse")()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keep nginx from buffering the stream.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if !resumed {
+		writeSSE(w, "", "reset", gin.H{})
+	}
+	for _, event := range missed {
+		writeSSE(w, event.ID, event.Type, event.Task)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind; the client resumes on reconnect.
+				return
+			}
+			err = writeSSE(w, event.ID, event.Type, event.Task)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return
+		}
+		w.Flush()
+	}
+}
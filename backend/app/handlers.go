@@ -0,0 +1,533 @@
+// Package app provides HTTP route handlers for the TaskBoard API,
+// including task creation, updates, retrieval, and deletion.
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// Stores groups the storage backends used by the HTTP handlers. Tasks is
+// required; routes backed by a nil store are not registered.
+type Stores struct {
+	Tasks      TaskStore
+	Boards     BoardStore
+	Labels     LabelStore
+	Checklists ChecklistStore
+	Comments   CommentStore
+	Users      UserStore
+	Keys       APIKeyStore
+	Members    MemberStore
+	Workspaces WorkspaceStore
+	Activity   ActivityStore
+	Webhooks   WebhookStore
+	Outbox     OutboxStore
+	// Tx runs transactions spanning the stores above.
+	Tx Transactor
+}
+
+// NewGormStores returns all stores backed by db.
+func NewGormStores(db *gorm.DB) Stores {
+	return Stores{
+		Tasks:      NewGormTaskStore(db),
+		Boards:     NewGormBoardStore(db),
+		Labels:     NewGormLabelStore(db),
+		Checklists: NewGormChecklistStore(db),
+		Comments:   NewGormCommentStore(db),
+		Users:      NewGormUserStore(db),
+		Keys:       NewGormAPIKeyStore(db),
+		Members:    NewGormMemberStore(db),
+		Workspaces: NewGormWorkspaceStore(db),
+		Activity:   NewGormActivityStore(db),
+		Webhooks:   NewGormWebhookStore(db),
+		Outbox:     NewGormOutboxStore(db),
+		Tx:         NewGormTransactor(db),
+	}
+}
+
+// Server holds the dependencies shared by the HTTP handlers.
+type Server struct {
+	tasks      TaskStore
+	boards     BoardStore
+	labels     LabelStore
+	checklists ChecklistStore
+	comments   CommentStore
+	users      UserStore
+	keys       APIKeyStore
+	members    MemberStore
+	workspaces WorkspaceStore
+	activity   ActivityStore
+	webhooks   WebhookStore
+	outbox     OutboxStore
+	tx         Transactor
+	auth       *Authenticator
+	// hub streams task changes to clients; see streamEvents and serveWS.
+	hub      *Hub
+	presence *Presence
+	// relay hands the outbox to the hub and other sinks; see RunRelay.
+	relay *OutboxRelay
+	// privateWebhooks lets webhooks target private addresses; see
+	// AllowPrivateWebhookTargets.
+	privateWebhooks bool
+}
+
+// NewServer returns a Server whose handlers read and write through stores.
+// Requests to the API must carry an access token issued by auth; a nil
+// auth disables authentication, leaving every request anonymous.
+func NewServer(stores Stores, auth *Authenticator) *Server {
+	s := &Server{
+		tasks:      stores.Tasks,
+		boards:     stores.Boards,
+		labels:     stores.Labels,
+		checklists: stores.Checklists,
+		comments:   stores.Comments,
+		users:      stores.Users,
+		keys:       stores.Keys,
+		members:    stores.Members,
+		workspaces: stores.Workspaces,
+		activity:   stores.Activity,
+		webhooks:   stores.Webhooks,
+		tx:         stores.Tx,
+		auth:       auth,
+		hub:        NewHub(),
+		presence:   NewPresence(),
+	}
+
+	// Task changes go through the outbox when the stores can write it in
+	// the transaction of the change. Sinks writing to the database come
+	// first, so that a failing one rolls back before anything was
+	// published.
+	if stores.Outbox != nil && stores.Tx != nil {
+		s.outbox = stores.Outbox
+		var sinks []EventSink
+		if stores.Webhooks != nil {
+			sinks = append(sinks, WebhookSink{Webhooks: stores.Webhooks})
+		}
+		sinks = append(sinks, TaskMetricsSink{Tasks: stores.Tasks}, LogSink{}, HubSink{Hub: s.hub})
+		s.relay = NewOutboxRelay(stores.Outbox, stores.Tx, sinks...)
+	}
+	return s
+}
+
+// Close disconnects the clients of GET /events and /ws, which would
+// otherwise keep the HTTP server from shutting down.
+func (s *Server) Close() {
+	s.hub.Close()
+}
+
+// refreshTaskMetrics recomputes the task gauges in the background, unless
+// the outbox relay does so once the change is committed. The request
+// context is detached from cancellation so the queries are not aborted
+// once the response has been written.
+func (s *Server) refreshTaskMetrics(c *gin.Context) {
+	if s.relay != nil {
+		return
+	}
+	go UpdateTaskMetrics(context.WithoutCancel(c.Request.Context()), s.tasks)
+}
+
+// routeID reads a numeric route parameter, returning false when it is
+// not a valid ID.
+func routeID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, strconv.IntSize)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIDParam reads a numeric route parameter, writing a 400 response
+// and returning false when it is not a valid ID.
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, ok := routeID(c, name)
+	if !ok {
+		writeError(c, http.StatusBadRequest, "invalid "+name)
+	}
+	return id, ok
+}
+
+// getTasks returns one page of tasks matching the query filters, newest
+// first unless another sort is requested. When more tasks remain, the URL
+// of the next page is returned in a Link header. Clients may revalidate
+// the page with If-None-Match.
+func (s *Server) getTasks(c *gin.Context) {
+	filter, opts, err := parseTaskQuery(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Fetch one extra task to find out whether another page follows.
+	pageSize := opts.Limit
+	opts.Limit++
+
+	var tasks []Task
+	err = TrackDBOperation(c.Request.Context(), "query_all_tasks", func(ctx context.Context) error {
+		var err error
+		tasks, err = s.tasks.List(ctx, filter, opts)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch tasks")
+		return
+	}
+
+	if len(tasks) > pageSize {
+		tasks = tasks[:pageSize]
+		setNextLink(c, encodeCursor(&tasks[pageSize-1], opts.Sort))
+	}
+
+	// Update metrics after successful retrieval
+	s.refreshTaskMetrics(c)
+
+	writeCachedJSON(c, tasks)
+}
+
+// getTask returns a single task, with its version as ETag.
+func (s *Server) getTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "find_task", func(ctx context.Context) error {
+		var err error
+		task, err = s.tasks.Get(ctx, id)
+		return err
+	})
+
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+	case err != nil:
+		writeInternalError(c, err, "failed to fetch task")
+	default:
+		writeTask(c, http.StatusOK, task)
+	}
+}
+
+// TaskFields are the editable fields of a task. Creating, replacing and
+// patching a task validate them alike.
+type TaskFields struct {
+	Title       string     `json:"title" binding:"required,min=1,max=200"`
+	Description string     `json:"description" binding:"max=10000"`
+	Priority    Priority   `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	DueAt       *time.Time `json:"due_at"`
+	// AutoComplete completes the task once its whole checklist is done.
+	AutoComplete bool `json:"auto_complete"`
+	// Recurrence is an iCalendar RRULE such as FREQ=WEEKLY;BYDAY=MO.
+	Recurrence string `json:"recurrence" binding:"omitempty,max=255,rrule"`
+}
+
+// apply copies the fields onto task, defaulting the priority to medium.
+func (f *TaskFields) apply(task *Task) {
+	task.Title = f.Title
+	task.Description = f.Description
+	task.Priority = f.Priority
+	task.DueAt = f.DueAt
+	task.AutoComplete = f.AutoComplete
+	task.Recurrence = f.Recurrence
+	if task.Priority == "" {
+		task.Priority = PriorityMedium
+	}
+}
+
+// CreateTaskInput represents the expected payload for creating a new task.
+type CreateTaskInput struct {
+	TaskFields
+	// ColumnID places the task at the end of a board column.
+	ColumnID *uint `json:"column_id"`
+}
+
+// createTask handles the creation of a new task.
+func (s *Server) createTask(c *gin.Context) {
+	var input CreateTaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	if input.ColumnID != nil {
+		if s.boards == nil {
+			writeError(c, http.StatusBadRequest, "boards are not supported")
+			return
+		}
+		if !s.authorizeColumn(c, *input.ColumnID, RoleEditor) {
+			return
+		}
+	} else if !s.authorizeWorkspace(c, RoleEditor) {
+		return
+	}
+
+	var created *Task
+	err := TrackDBOperation(c.Request.Context(), "create_task", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			created, err = s.insertTask(ctx, &input)
+			return err
+		})
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to create task")
+		return
+	}
+
+	// Update metrics after successful creation
+	s.refreshTaskMetrics(c)
+
+	writeTask(c, http.StatusCreated, created)
+}
+
+// insertTask creates a task from validated input, placing it at the end
+// of its column when one is given, and records its creation.
+func (s *Server) insertTask(ctx context.Context, input *CreateTaskInput) (*Task, error) {
+	var task Task
+	input.apply(&task)
+	if err := s.tasks.Create(ctx, &task); err != nil {
+		return nil, err
+	}
+
+	created := &task
+	if input.ColumnID != nil {
+		var err error
+		if created, err = s.boards.MoveTask(ctx, task.ID, *input.ColumnID, math.MaxInt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.recordTaskEvent(ctx, TaskCreated, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateTaskInput is the full representation of a task's editable
+// fields: the body of PUT, and the document a PATCH merge patch applies to.
+type UpdateTaskInput struct {
+	TaskFields
+	Completed bool `json:"completed"`
+}
+
+// updateInputOf returns the editable fields of task.
+func updateInputOf(task *Task) UpdateTaskInput {
+	return UpdateTaskInput{
+		TaskFields: TaskFields{
+			Title:        task.Title,
+			Description:  task.Description,
+			Priority:     task.Priority,
+			DueAt:        task.DueAt,
+			AutoComplete: task.AutoComplete,
+			Recurrence:   task.Recurrence,
+		},
+		Completed: task.Completed,
+	}
+}
+
+// findTaskToUpdate loads the task a PUT or PATCH request changes and
+// checks its If-Match header, writing the error response on failure.
+func (s *Server) findTaskToUpdate(c *gin.Context) (*Task, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "find_task", func(ctx context.Context) error {
+		var err error
+		task, err = s.tasks.Get(ctx, id)
+		return err
+	})
+
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+		return nil, false
+	case err != nil:
+		writeInternalError(c, err, "failed to fetch task")
+		return nil, false
+	}
+	return task, checkIfMatch(c, task)
+}
+
+// updateTask replaces the editable fields of a task; fields missing from
+// the body are reset to their defaults. With If-Match, the task is only
+// changed while its ETag matches; a concurrent change between reading and
+// saving the task is reported as a conflict.
+func (s *Server) updateTask(c *gin.Context) {
+	task, ok := s.findTaskToUpdate(c)
+	if !ok {
+		return
+	}
+
+	var input UpdateTaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	s.saveTask(c, task, &input)
+}
+
+// patchTask changes the fields of a task listed in an RFC 7396 merge
+// patch, where null resets a field to its default. The patched task is
+// validated like a PUT body.
+func (s *Server) patchTask(c *gin.Context) {
+	if ct := c.ContentType(); ct != "application/merge-patch+json" && ct != "application/json" {
+		writeError(c, http.StatusUnsupportedMediaType, "patch must be application/merge-patch+json")
+		return
+	}
+
+	task, ok := s.findTaskToUpdate(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	input, err := applyMergePatch(updateInputOf(task), patch)
+	if err != nil {
+		writeInputError(c, err)
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	s.saveTask(c, task, &input)
+}
+
+// saveTask saves validated input to task and responds with the result.
+func (s *Server) saveTask(c *gin.Context, task *Task, input *UpdateTaskInput) {
+	err := TrackDBOperation(c.Request.Context(), "update_task", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			task, err = s.replaceTask(ctx, task, input)
+			return err
+		})
+	})
+
+	switch {
+	case errors.Is(err, ErrVersionConflict) && c.GetHeader("If-Match") != "":
+		writeError(c, http.StatusPreconditionFailed, err.Error())
+		return
+	case errors.Is(err, ErrVersionConflict):
+		writeError(c, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeInternalError(c, err, "failed to update task")
+		return
+	}
+
+	// Update metrics after successful update
+	s.refreshTaskMetrics(c)
+
+	writeTask(c, http.StatusOK, task)
+}
+
+// replaceTask applies validated input to task and saves it, keeping its
+// board column in line with its completion, and records the change.
+// Completing a recurring task creates its next occurrence.
+func (s *Server) replaceTask(ctx context.Context, task *Task, input *UpdateTaskInput) (*Task, error) {
+	before := *task
+	completed := input.Completed
+	// Turning AutoComplete on completes a task whose checklist is done.
+	if input.AutoComplete && !task.AutoComplete && task.Progress.complete() {
+		completed = true
+	}
+	completionChanged := completed != task.Completed
+
+	input.apply(task)
+	task.Completed = completed
+	// Completing a recurring task hands its rule over to the next
+	// occurrence.
+	var rule string
+	if completionChanged && completed {
+		rule, task.Recurrence = task.Recurrence, ""
+	}
+
+	if err := s.tasks.Update(ctx, task); err != nil {
+		return nil, err
+	}
+	if completionChanged {
+		var err error
+		if task, err = s.syncCompletedColumn(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.recordTaskEvent(ctx, TaskUpdated, &before, task); err != nil {
+		return nil, err
+	}
+	if rule != "" {
+		if _, err := s.createOccurrence(ctx, task, rule, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return task, nil
+}
+
+// deleteTask moves a task to the trash by ID. With If-Match, the task is
+// only deleted while its ETag matches.
+func (s *Server) deleteTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "find_task", func(ctx context.Context) error {
+		var err error
+		task, err = s.tasks.Get(ctx, id)
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrTaskNotFound) {
+		writeInternalError(c, err, "failed to delete task")
+		return
+	}
+	if !checkIfMatch(c, task) {
+		return
+	}
+
+	// Deleting a missing task succeeds without recording anything.
+	if task != nil {
+		err := TrackDBOperation(c.Request.Context(), "delete_task", func(ctx context.Context) error {
+			return s.inTx(ctx, func(ctx context.Context) error {
+				return s.trashTask(ctx, task)
+			})
+		})
+
+		if err != nil {
+			writeInternalError(c, err, "failed to delete task")
+			return
+		}
+	}
+
+	// Update metrics after successful deletion
+	s.refreshTaskMetrics(c)
+
+	c.Status(http.StatusNoContent)
+}
+
+// trashTask moves task to the trash and records its deletion.
+func (s *Server) trashTask(ctx context.Context, task *Task) error {
+	if err := s.tasks.Delete(ctx, task.ID); err != nil {
+		return err
+	}
+	return s.recordTaskEvent(ctx, TaskDeleted, task, nil)
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hubHistorySize is the number of recent events a Hub keeps for clients
+// resuming a stream.
+const hubHistorySize = 1024
+
+// subscriberBuffer is the number of events that may wait for a slow
+// subscriber before the Hub disconnects it.
+const subscriberBuffer = 64
+
+// HubEvent is a change to a task, as streamed to clients: one of
+// TaskCreated, TaskUpdated, TaskDeleted and TaskRestored with the task
+// after the change, or before it for deletions.
+type HubEvent struct {
+	// ID is <epoch>-<sequence>, where the epoch tells Hubs apart, so that
+	// IDs issued before a restart are not mistaken for new ones.
+	ID          string
+	Type        string
+	WorkspaceID uint
+	// BoardIDs are the boards the task was on before and after the change.
+	BoardIDs []uint
+	Task     *Task
+
+	seq uint64
+}
+
+// Hub is an in-process publish/subscribe hub for task changes. It keeps
+// the latest events so that a client reconnecting with the ID of the last
+// event it saw misses nothing, and drops subscribers that fall too far
+// behind; they can reconnect and resume the same way.
+type Hub struct {
+	epoch       string
+	mu          sync.Mutex
+	lastID      uint64
+	history     []HubEvent
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewHub returns a Hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 10),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// ValidEventID reports whether id has the form of a HubEvent ID. IDs of
+// the plain sequence form used by earlier versions are valid as well.
+func ValidEventID(id string) bool {
+	_, _, ok := parseEventID(id)
+	return ok
+}
+
+// parseEventID splits a HubEvent ID into its epoch and sequence number.
+func parseEventID(id string) (epoch string, seq uint64, ok bool) {
+	epoch, rest, found := strings.Cut(id, "-")
+	if !found {
+		epoch, rest = "", id
+	} else if _, err := strconv.ParseUint(epoch, 10, 64); err != nil {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseUint(rest, 10, 64)
+	return epoch, seq, err == nil
+}
+
+// Subscription receives the events of a workspace from a Hub.
+type Subscription struct {
+	ctx    context.Context
+	events chan HubEvent
+}
+
+// Events returns the events published since the subscription started. It
+// is closed once the subscription ends, either through Unsubscribe or
+// because the subscriber fell behind.
+func (s *Subscription) Events() <-chan HubEvent {
+	return s.events
+}
+
+// Publish numbers event and delivers it to the subscribers of its
+// workspace.
+func (h *Hub) Publish(event HubEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event.seq = h.lastID
+	event.ID = h.epoch + "-" + strconv.FormatUint(event.seq, 10)
+	if len(h.history) == hubHistorySize {
+		h.history = append(h.history[:0], h.history[1:]...)
+	}
+	h.history = append(h.history, event)
+
+	for sub := range h.subscribers {
+		if !inWorkspace(sub.ctx, event.WorkspaceID) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Subscribe starts a subscription to the events of the workspace of ctx.
+// With lastID set, it also returns the retained events published after
+// that one; resumed is false when some of them are no longer retained,
+// or lastID was issued by another Hub, such as before a restart. Once the
+// Hub is closed, the subscription ends right away.
+func (h *Hub) Subscribe(ctx context.Context, lastID string) (sub *Subscription, missed []HubEvent, resumed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{ctx: ctx, events: make(chan HubEvent, subscriberBuffer)}
+	if h.closed {
+		close(sub.events)
+		return sub, nil, true
+	}
+	h.subscribers[sub] = struct{}{}
+
+	if lastID == "" {
+		return sub, nil, true
+	}
+	epoch, last, ok := parseEventID(lastID)
+	if !ok || epoch != h.epoch || last > h.lastID {
+		return sub, nil, false
+	}
+	resumed = len(h.history) == 0 || h.history[0].seq <= last+1
+	for _, event := range h.history {
+		if event.seq > last && inWorkspace(ctx, event.WorkspaceID) {
+			missed = append(missed, event)
+		}
+	}
+	return sub, missed, resumed
+}
+
+// Unsubscribe ends a subscription. Ending it twice is not an error.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub)
+}
+
+// Close ends every subscription, and those started later, so that
+// streaming clients disconnect when the server shuts down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subscribers {
+		h.drop(sub)
+	}
+}
+
+// drop removes sub and closes its channel; h.mu must be held.
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// pendingKey is the context key of the events to publish once the
+// transaction started by Server.inTx commits.
+type pendingKey struct{}
+
+// pendingEvents collects the events of a transaction.
+type pendingEvents struct {
+	events []HubEvent
+	// outbox is set once the transaction wrote to the outbox.
+	outbox bool
+}
+
+// publish delivers events to the hub, or, inside Server.inTx, holds them
+// back until the transaction commits so that clients never see changes
+// that are rolled back.
+func (s *Server) publish(ctx context.Context, events ...HubEvent) {
+	if pending, ok := ctx.Value(pendingKey{}).(*pendingEvents); ok {
+		pending.events = append(pending.events, events...)
+		return
+	}
+	for _, event := range events {
+		s.hub.Publish(event)
+	}
+}
+
+// wakeRelay notifies the outbox relay of new events or, inside
+// Server.inTx, once the transaction commits.
+func (s *Server) wakeRelay(ctx context.Context) {
+	if pending, ok := ctx.Value(pendingKey{}).(*pendingEvents); ok {
+		pending.outbox = true
+		return
+	}
+	if s.relay != nil {
+		s.relay.Notify()
+	}
+}
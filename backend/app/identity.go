@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// identityKey is the gin context key holding the authenticated Identity.
+const identityKey = "identity"
+
+// actorKey is the context key holding the ID of the user a request acts
+// on behalf of.
+type actorKey struct{}
+
+// withActor returns a copy of ctx acting on behalf of the given user.
+func withActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// actorFrom returns the user ctx acts on behalf of, if any.
+func actorFrom(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(actorKey{}).(uint)
+	return id, ok
+}
+
+// authenticate rejects requests without a valid access token or API key
+// with 401 and records the identity of the others for the handlers. API
+// keys are accepted in the X-API-Key header or as bearer tokens. The
+// request context is limited to the user's workspace and acts on the
+// user's behalf.
+func (s *Server) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := s.identify(c)
+		if err != nil {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+
+		c.Set(identityKey, identity)
+		ctx := WithWorkspace(c.Request.Context(), identity.WorkspaceID)
+		c.Request = c.Request.WithContext(withActor(ctx, identity.UserID))
+		c.Next()
+	}
+}
+
+// identify returns the identity proven by the request's credential. The
+// returned errors are safe to show to the client.
+func (s *Server) identify(c *gin.Context) (*Identity, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return s.identifyAPIKey(c.Request.Context(), key)
+	}
+
+	token, ok := bearerToken(c)
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		return s.identifyAPIKey(c.Request.Context(), token)
+	}
+
+	claims, err := s.auth.verify(token, tokenAccess)
+	if errors.Is(err, ErrTokenExpired) {
+		return nil, errors.New("token expired")
+	}
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	return claims.identity()
+}
+
+// wsBearerProtocol is the WebSocket subprotocol carrying an access token,
+// as in new WebSocket(url, ["bearer", token]), for browsers cannot set the
+// Authorization header of WebSocket handshakes.
+const wsBearerProtocol = "bearer"
+
+// bearerToken returns the token of the Authorization header or, failing
+// that, of the subprotocols of a WebSocket handshake.
+func bearerToken(c *gin.Context) (string, bool) {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return token, token != ""
+	}
+	protocols := strings.Split(c.GetHeader("Sec-WebSocket-Protocol"), ",")
+	if len(protocols) == 2 && strings.TrimSpace(protocols[0]) == wsBearerProtocol {
+		token := strings.TrimSpace(protocols[1])
+		return token, token != ""
+	}
+	return "", false
+}
+
+// abortUnauthorized ends the request with 401 and a Bearer challenge.
+func abortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="taskboard", error="invalid_token"`)
+	writeError(c, http.StatusUnauthorized, message)
+}
+
+// currentIdentity returns the authenticated user of the request, if any.
+// Requests are anonymous only when authentication is disabled.
+func currentIdentity(c *gin.Context) (*Identity, bool) {
+	value, ok := c.Get(identityKey)
+	if !ok {
+		return nil, false
+	}
+	return value.(*Identity), true
+}
+
+// currentUserID returns the ID of the authenticated user, if any.
+func currentUserID(c *gin.Context) (uint, bool) {
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return 0, false
+	}
+	return identity.UserID, true
+}
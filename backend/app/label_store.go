@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLabelNotFound is returned when no label matches the requested ID.
+	ErrLabelNotFound = errors.New("label not found")
+	// ErrLabelExists is returned when creating or renaming a label to a
+	// name that is already taken.
+	ErrLabelExists = errors.New("label already exists")
+)
+
+// LabelStore persists labels and their association with tasks. It shares
+// the tasks table with the TaskStore of the same backend.
+type LabelStore interface {
+	// ListLabels returns every label ordered by name.
+	ListLabels(ctx context.Context) ([]Label, error)
+	GetLabel(ctx context.Context, id uint) (*Label, error)
+	CreateLabel(ctx context.Context, label *Label) error
+	UpdateLabel(ctx context.Context, label *Label) error
+	// DeleteLabel removes a label and detaches it from every task.
+	DeleteLabel(ctx context.Context, id uint) error
+
+	// AttachLabels adds labels to a task and returns the updated task.
+	// Labels already attached are ignored.
+	AttachLabels(ctx context.Context, taskID uint, labelIDs []uint) (*Task, error)
+	// DetachLabel removes a label from a task and returns the updated task.
+	DetachLabel(ctx context.Context, taskID, labelID uint) (*Task, error)
+}
+
+// GormLabelStore is a LabelStore backed by a GORM database connection.
+type GormLabelStore struct {
+	db *gorm.DB
+}
+
+// NewGormLabelStore returns a LabelStore that persists labels through db.
+func NewGormLabelStore(db *gorm.DB) *GormLabelStore {
+	return &GormLabelStore{db: db}
+}
+
+// orderedLabels sorts preloaded or queried labels for display.
+func orderedLabels(db *gorm.DB) *gorm.DB {
+	return db.Order("labels.name asc")
+}
+
+// labelError maps a unique constraint violation to ErrLabelExists. It
+// relies on OpenDB enabling error translation.
+func labelError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrLabelExists
+	}
+	return err
+}
+
+// ListLabels returns every label ordered by name.
+func (s *GormLabelStore) ListLabels(ctx context.Context) ([]Label, error) {
+	labels := []Label{}
+	err := orderedLabels(dbFor(ctx, s.db)).Find(&labels).Error
+	return labels, err
+}
+
+// GetLabel returns the label with the given ID.
+func (s *GormLabelStore) GetLabel(ctx context.Context, id uint) (*Label, error) {
+	return findLabel(dbFor(ctx, s.db), id)
+}
+
+// findLabel loads a label through db, which may be a transaction.
+func findLabel(db *gorm.DB, id uint) (*Label, error) {
+	var label Label
+	err := db.First(&label, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLabelNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// CreateLabel inserts a new label.
+func (s *GormLabelStore) CreateLabel(ctx context.Context, label *Label) error {
+	return labelError(dbFor(ctx, s.db).Create(label).Error)
+}
+
+// UpdateLabel saves all fields of an existing label.
+func (s *GormLabelStore) UpdateLabel(ctx context.Context, label *Label) error {
+	return labelError(dbFor(ctx, s.db).Save(label).Error)
+}
+
+// DeleteLabel removes a label; the join table cascades the deletion to
+// its task associations.
+func (s *GormLabelStore) DeleteLabel(ctx context.Context, id uint) error {
+	res := dbFor(ctx, s.db).Delete(&Label{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLabelNotFound
+	}
+	return nil
+}
+
+// AttachLabels adds the given labels to a task. It fails with
+// ErrLabelNotFound, attaching nothing, if any of the labels is missing.
+func (s *GormLabelStore) AttachLabels(ctx context.Context, taskID uint, labelIDs []uint) (*Task, error) {
+	var task *Task
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if task, err = findTask(tx, taskID); err != nil {
+			return err
+		}
+
+		ids := slices.Compact(slices.Sorted(slices.Values(labelIDs)))
+		var labels []Label
+		if err := tx.Find(&labels, ids).Error; err != nil {
+			return err
+		}
+		if len(labels) != len(ids) {
+			return ErrLabelNotFound
+		}
+
+		// Only write the join rows; the labels themselves are unchanged.
+		if err := tx.Omit("Labels.*").Model(task).Association("Labels").Append(&labels); err != nil {
+			return err
+		}
+
+		task, err = findTask(tx, taskID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// DetachLabel removes a label from a task. Detaching a label that is not
+// attached is not an error.
+func (s *GormLabelStore) DetachLabel(ctx context.Context, taskID, labelID uint) (*Task, error) {
+	var task *Task
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if task, err = findTask(tx, taskID); err != nil {
+			return err
+		}
+		if _, err := findLabel(tx, labelID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(task).Association("Labels").Delete(&Label{ID: labelID}); err != nil {
+			return err
+		}
+
+		task, err = findTask(tx, taskID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
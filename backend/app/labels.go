@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultLabelColor is used when a label is created without a color.
+const defaultLabelColor = "#64748b"
+
+// writeLabelError maps LabelStore errors to HTTP responses.
+func writeLabelError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrLabelNotFound):
+		writeError(c, http.StatusNotFound, "label not found")
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+	case errors.Is(err, ErrLabelExists):
+		writeError(c, http.StatusConflict, "a label with this name already exists")
+	default:
+		writeInternalError(c, err, fallback)
+	}
+}
+
+// listLabels returns all labels ordered by name.
+func (s *Server) listLabels(c *gin.Context) {
+	var labels []Label
+	err := TrackDBOperation(c.Request.Context(), "query_all_labels", func(ctx context.Context) error {
+		var err error
+		labels, err = s.labels.ListLabels(ctx)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch labels")
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+// CreateLabelInput represents the expected payload for creating a label.
+// Color is a #rrggbb hex code.
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required,min=1,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor,len=7"`
+}
+
+// createLabel creates a label.
+func (s *Server) createLabel(c *gin.Context) {
+	var input CreateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	label := Label{Name: input.Name, Color: input.Color}
+	if label.Color == "" {
+		label.Color = defaultLabelColor
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "create_label", func(ctx context.Context) error {
+		return s.labels.CreateLabel(ctx, &label)
+	})
+
+	if err != nil {
+		writeLabelError(c, err, "failed to create label")
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+// getLabel returns a single label.
+func (s *Server) getLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var label *Label
+	err := TrackDBOperation(c.Request.Context(), "find_label", func(ctx context.Context) error {
+		var err error
+		label, err = s.labels.GetLabel(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		writeLabelError(c, err, "failed to fetch label")
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+// UpdateLabelInput represents the fields that can be updated in a label.
+type UpdateLabelInput struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color" binding:"omitempty,hexcolor,len=7"`
+}
+
+// updateLabel renames or recolors a label.
+func (s *Server) updateLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input UpdateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var label *Label
+	err := TrackDBOperation(c.Request.Context(), "update_label", func(ctx context.Context) error {
+		var err error
+		if label, err = s.labels.GetLabel(ctx, id); err != nil {
+			return err
+		}
+		if input.Name != nil {
+			label.Name = *input.Name
+		}
+		if input.Color != nil {
+			label.Color = *input.Color
+		}
+		return s.labels.UpdateLabel(ctx, label)
+	})
+
+	if err != nil {
+		writeLabelError(c, err, "failed to update label")
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+// deleteLabel deletes a label and detaches it from all tasks.
+func (s *Server) deleteLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_label", func(ctx context.Context) error {
+		return s.labels.DeleteLabel(ctx, id)
+	})
+
+	if err != nil {
+		writeLabelError(c, err, "failed to delete label")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AttachLabelsInput lists the labels to attach to a task.
+type AttachLabelsInput struct {
+	LabelIDs []uint `json:"label_ids" binding:"required,min=1,dive,min=1"`
+}
+
+// attachLabels attaches one or more labels to a task.
+func (s *Server) attachLabels(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input AttachLabelsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "attach_labels", func(ctx context.Context) error {
+		var err error
+		task, err = s.labels.AttachLabels(ctx, id, input.LabelIDs)
+		return err
+	})
+
+	if err != nil {
+		writeLabelError(c, err, "failed to attach labels")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// detachLabel removes a label from a task.
+func (s *Server) detachLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	labelID, ok := parseIDParam(c, "label_id")
+	if !ok {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "detach_label", func(ctx context.Context) error {
+		var err error
+		task, err = s.labels.DetachLabel(ctx, id, labelID)
+		return err
+	})
+
+	if err != nil {
+		writeLabelError(c, err, "failed to detach label")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
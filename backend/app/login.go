@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// hashPassword returns the bcrypt hash stored for password.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// dummyPasswordHash is compared against when a login names no user with
+// a password, so that the response takes as long as for a wrong password
+// and does not reveal which accounts exist.
+var dummyPasswordHash, _ = hashPassword("not the password of any user")
+
+// normalizeEmail makes email lookups case-insensitive.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RegisterInput represents the expected payload for signing up. Workspace
+// names the workspace created for the new user; it defaults to the user's
+// name.
+type RegisterInput struct {
+	Name      string `json:"name" binding:"required,min=1,max=100"`
+	Email     string `json:"email" binding:"required,email,max=255"`
+	Password  string `json:"password" binding:"required,min=8,max=72"`
+	Workspace string `json:"workspace" binding:"max=100"`
+}
+
+// register creates a user with a password in a new workspace and logs
+// them in. Further users join the workspace when one of its members
+// creates them through POST /api/users.
+func (s *Server) register(c *gin.Context) {
+	var input RegisterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	hash, err := hashPassword(input.Password)
+	if err != nil {
+		writeInternalError(c, err, "failed to create user")
+		return
+	}
+
+	// The user who creates a workspace owns it.
+	user := User{Name: input.Name, Email: normalizeEmail(input.Email), PasswordHash: hash, Role: RoleOwner}
+	workspace := Workspace{Name: input.Workspace}
+	if workspace.Name == "" {
+		workspace.Name = input.Name
+	}
+	err = TrackDBOperation(c.Request.Context(), "create_user", func(ctx context.Context) error {
+		return s.workspaces.CreateWorkspace(ctx, &workspace, &user)
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to create user")
+		return
+	}
+
+	s.writeTokens(c, http.StatusCreated, &user)
+}
+
+// LoginInput represents the credentials exchanged for tokens. Workspace
+// picks the workspace to log in to when the same email and password
+// belong to users in several.
+type LoginInput struct {
+	Email     string `json:"email" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Workspace uint   `json:"workspace_id"`
+}
+
+// login exchanges an email and password for an access and refresh token.
+func (s *Server) login(c *gin.Context) {
+	var input LoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var users []User
+	err := TrackDBOperation(c.Request.Context(), "find_user", func(ctx context.Context) error {
+		var err error
+		users, err = s.users.ListUsersByEmail(ctx, normalizeEmail(input.Email))
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to log in")
+		return
+	}
+	// Only users whose password matches are told apart, so that nobody
+	// learns which workspaces an email belongs to without its password.
+	var matches []*User
+	compared := false
+	for i := range users {
+		user := &users[i]
+		if user.PasswordHash == "" || (input.Workspace != 0 && user.WorkspaceID != input.Workspace) {
+			continue
+		}
+		compared = true
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)) == nil {
+			matches = append(matches, user)
+		}
+	}
+	if !compared {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(input.Password))
+	}
+	switch len(matches) {
+	case 0:
+		writeError(c, http.StatusUnauthorized, "invalid email or password")
+	case 1:
+		s.writeTokens(c, http.StatusOK, matches[0])
+	default:
+		writeError(c, http.StatusConflict, "the email belongs to several workspaces; pass workspace_id to pick one")
+	}
+}
+
+// RefreshInput carries the refresh token exchanged for a new token pair.
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// refresh exchanges a valid refresh token for a new token pair, as long as
+// its user still exists.
+func (s *Server) refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	claims, err := s.auth.verify(input.RefreshToken, tokenRefresh)
+	if err != nil {
+		writeError(c, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	identity, err := claims.identity()
+	if err != nil {
+		writeError(c, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+
+	var user *User
+	err = TrackDBOperation(c.Request.Context(), "find_user", func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetUser(ctx, identity.UserID)
+		return err
+	})
+
+	if errors.Is(err, ErrUserNotFound) {
+		writeError(c, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	if err != nil {
+		writeInternalError(c, err, "failed to refresh token")
+		return
+	}
+
+	s.writeTokens(c, http.StatusOK, user)
+}
+
+// writeTokens responds with a new token pair for user.
+func (s *Server) writeTokens(c *gin.Context, status int, user *User) {
+	tokens, err := s.auth.IssueTokens(user)
+	if err != nil {
+		writeInternalError(c, err, "failed to issue token")
+		return
+	}
+	c.JSON(status, tokens)
+}
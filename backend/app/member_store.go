@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMemberNotFound is returned when the user is not a member of the
+	// board.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrMemberExists is returned when adding a user who already is a
+	// member of the board.
+	ErrMemberExists = errors.New("member already exists")
+	// ErrLastOwner is returned when removing or demoting the only owner
+	// of a board.
+	ErrLastOwner = errors.New("board must keep an owner")
+)
+
+// MemberStore persists the members of boards and their roles. It shares
+// the boards table with the BoardStore of the same backend.
+type MemberStore interface {
+	// ListMembers returns the members of a board, owners first.
+	ListMembers(ctx context.Context, boardID uint) ([]BoardMember, error)
+	// AddMember adds a user to a board and fills in its User.
+	AddMember(ctx context.Context, member *BoardMember) error
+	// UpdateMember changes the role of a member and returns it.
+	UpdateMember(ctx context.Context, boardID, userID uint, role Role) (*BoardMember, error)
+	RemoveMember(ctx context.Context, boardID, userID uint) error
+
+	// Role returns the role of a user on a board, or "" when the user is
+	// not a member. Boards without members are open: everyone holds their
+	// workspace role on them.
+	Role(ctx context.Context, boardID, userID uint) (Role, error)
+}
+
+// GormMemberStore is a MemberStore backed by a GORM database connection.
+type GormMemberStore struct {
+	db *gorm.DB
+}
+
+// NewGormMemberStore returns a MemberStore that persists members through db.
+func NewGormMemberStore(db *gorm.DB) *GormMemberStore {
+	return &GormMemberStore{db: db}
+}
+
+// orderedMembers sorts members by decreasing role, then by name.
+func orderedMembers(db *gorm.DB) *gorm.DB {
+	return db.Joins("User").Order(
+		"CASE board_members.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, \"User\".name asc, board_members.user_id asc")
+}
+
+// findMember loads a member and its user through db, which may be a
+// transaction.
+func findMember(db *gorm.DB, boardID, userID uint) (*BoardMember, error) {
+	var member BoardMember
+	err := db.Joins("User").Where("board_members.board_id = ? AND board_members.user_id = ?", boardID, userID).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// countOwners returns the number of owners of a board.
+func countOwners(tx *gorm.DB, boardID uint) (int64, error) {
+	var owners int64
+	err := tx.Model(&BoardMember{}).Where("board_id = ? AND role = ?", boardID, RoleOwner).Count(&owners).Error
+	return owners, err
+}
+
+// keepOwner fails with ErrLastOwner when member is the only owner of its
+// board, so that it may not lose the role.
+func keepOwner(tx *gorm.DB, member *BoardMember) error {
+	if member.Role != RoleOwner {
+		return nil
+	}
+	owners, err := countOwners(tx, member.BoardID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// ListMembers returns the members of a board with their users.
+func (s *GormMemberStore) ListMembers(ctx context.Context, boardID uint) ([]BoardMember, error) {
+	db := dbFor(ctx, s.db)
+	if err := db.First(&Board{}, boardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoardNotFound
+		}
+		return nil, err
+	}
+
+	members := []BoardMember{}
+	err := orderedMembers(db).Where("board_members.board_id = ?", boardID).Find(&members).Error
+	return members, err
+}
+
+// AddMember inserts a member after checking that its board and user exist.
+// Boards without an owner only accept an owner.
+func (s *GormMemberStore) AddMember(ctx context.Context, member *BoardMember) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Board{}, member.BoardID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBoardNotFound
+			}
+			return err
+		}
+		user, err := findUser(tx, member.UserID)
+		if err != nil {
+			return err
+		}
+
+		// The first member restricts an open board, so it must own it.
+		if member.Role != RoleOwner {
+			owners, err := countOwners(tx, member.BoardID)
+			if err != nil {
+				return err
+			}
+			if owners == 0 {
+				return ErrLastOwner
+			}
+		}
+
+		if err := tx.Omit("User").Create(member).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrMemberExists
+			}
+			return err
+		}
+		member.User = user
+		return nil
+	})
+}
+
+// UpdateMember changes the role of a member, keeping at least one owner.
+func (s *GormMemberStore) UpdateMember(ctx context.Context, boardID, userID uint, role Role) (*BoardMember, error) {
+	var member *BoardMember
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if member, err = findMember(tx, boardID, userID); err != nil {
+			return err
+		}
+		if member.Role == role {
+			return nil
+		}
+		if err := keepOwner(tx, member); err != nil {
+			return err
+		}
+
+		member.Role = role
+		return tx.Omit("User").Save(member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember removes a member, keeping at least one owner.
+func (s *GormMemberStore) RemoveMember(ctx context.Context, boardID, userID uint) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		member, err := findMember(tx, boardID, userID)
+		if err != nil {
+			return err
+		}
+		if err := keepOwner(tx, member); err != nil {
+			return err
+		}
+		return tx.Delete(&BoardMember{}, "board_id = ? AND user_id = ?", boardID, userID).Error
+	})
+}
+
+// Role returns the role of a user on a board.
+func (s *GormMemberStore) Role(ctx context.Context, boardID, userID uint) (Role, error) {
+	db := dbFor(ctx, s.db)
+
+	var member BoardMember
+	res := db.Where("board_id = ? AND user_id = ?", boardID, userID).Limit(1).Find(&member)
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected > 0 {
+		return member.Role, nil
+	}
+
+	var members int64
+	if err := db.Model(&BoardMember{}).Where("board_id = ?", boardID).Count(&members).Error; err != nil {
+		return "", err
+	}
+	if members > 0 {
+		return "", nil
+	}
+
+	var roles []Role
+	if err := db.Model(&User{}).Where("id = ?", userID).Limit(1).Pluck("role", &roles).Error; err != nil || len(roles) == 0 {
+		return "", err
+	}
+	return roles[0], nil
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeMemberError maps MemberStore errors to HTTP responses.
+func writeMemberError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrBoardNotFound):
+		writeError(c, http.StatusNotFound, "board not found")
+	case errors.Is(err, ErrUserNotFound):
+		writeError(c, http.StatusNotFound, "user not found")
+	case errors.Is(err, ErrMemberNotFound):
+		writeError(c, http.StatusNotFound, "member not found")
+	case errors.Is(err, ErrMemberExists):
+		writeError(c, http.StatusConflict, "user is already a member of the board")
+	case errors.Is(err, ErrLastOwner):
+		writeError(c, http.StatusConflict, "board must keep an owner")
+	default:
+		writeInternalError(c, err, fallback)
+	}
+}
+
+// listMembers returns the members of a board, owners first.
+func (s *Server) listMembers(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var members []BoardMember
+	err := TrackDBOperation(c.Request.Context(), "query_board_members", func(ctx context.Context) error {
+		var err error
+		members, err = s.members.ListMembers(ctx, boardID)
+		return err
+	})
+
+	if err != nil {
+		writeMemberError(c, err, "failed to fetch members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMemberInput represents the expected payload for adding a board member.
+type AddMemberInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+	Role   Role `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// addMember grants a user a role on a board. The first member of a board
+// must be an owner.
+func (s *Server) addMember(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input AddMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	member := BoardMember{BoardID: boardID, UserID: input.UserID, Role: input.Role}
+	err := TrackDBOperation(c.Request.Context(), "create_board_member", func(ctx context.Context) error {
+		return s.members.AddMember(ctx, &member)
+	})
+
+	if err != nil {
+		writeMemberError(c, err, "failed to add member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMemberInput represents the payload for changing a member's role.
+type UpdateMemberInput struct {
+	Role Role `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// updateMember changes the role of a board member.
+func (s *Server) updateMember(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var input UpdateMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var member *BoardMember
+	err := TrackDBOperation(c.Request.Context(), "update_board_member", func(ctx context.Context) error {
+		var err error
+		member, err = s.members.UpdateMember(ctx, boardID, userID, input.Role)
+		return err
+	})
+
+	if err != nil {
+		writeMemberError(c, err, "failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// removeMember revokes a user's role on a board.
+func (s *Server) removeMember(c *gin.Context) {
+	boardID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_board_member", func(ctx context.Context) error {
+		return s.members.RemoveMember(ctx, boardID, userID)
+	})
+
+	if err != nil {
+		writeMemberError(c, err, "failed to remove member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package app
+
+import "encoding/json"
+
+// applyMergePatch returns doc, which must encode as a JSON object, with
+// an RFC 7396 JSON merge patch applied.
+func applyMergePatch[T any](doc T, patch []byte) (T, error) {
+	var patched T
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return patched, err
+	}
+
+	var target, changes any
+	if err := json.Unmarshal(current, &target); err != nil {
+		return patched, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return patched, err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, changes))
+	if err != nil {
+		return patched, err
+	}
+	err = json.Unmarshal(merged, &patched)
+	return patched, err
+}
+
+// mergePatch returns target with patch merged in: members of a patch
+// object replace those of the target, recursively, and null members
+// remove them. A patch that is not an object replaces the target.
+func mergePatch(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	merged, ok := target.(map[string]any)
+	if !ok {
+		merged = map[string]any{}
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = mergePatch(merged[name], value)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,342 @@
+// Package app defines the data models used by the TaskBoard backend service.
+package app
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Priority ranks how urgent a task is.
+type Priority string
+
+// Supported task priorities, from least to most urgent.
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// valid reports whether p is one of the supported priorities.
+func (p Priority) valid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// Task represents a task item stored in the database.
+// It includes metadata fields automatically managed by GORM.
+// A task may be placed on a board, in which case ColumnID and Position
+// locate it within the board's columns. Labels are attached and detached
+// through the LabelStore, checklist items managed through the
+// ChecklistStore, and assignee and watchers set through the UserStore,
+// rather than saved with the task.
+type Task struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint       `json:"-" gorm:"index;not null;default:0"`
+	Title       string     `json:"title"`
+	Description string     `json:"description" gorm:"type:text;not null;default:''"` // Markdown
+	Priority    Priority   `json:"priority" gorm:"size:16;not null;default:medium;index"`
+	DueAt       *time.Time `json:"due_at" gorm:"index"`
+	Completed   bool       `json:"completed"`
+	BoardID     *uint      `json:"board_id" gorm:"index"`
+	ColumnID    *uint      `json:"column_id" gorm:"index"`
+	Position    int        `json:"position"`
+	Labels      []Label    `json:"labels" gorm:"many2many:task_labels;constraint:OnDelete:CASCADE"`
+	AssigneeID  *uint      `json:"assignee_id" gorm:"index"`
+	Assignee    *User      `json:"assignee,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	Watchers    []User     `json:"watchers" gorm:"many2many:task_watchers;constraint:OnDelete:CASCADE"`
+	// AutoComplete completes the task once every checklist item is done.
+	AutoComplete bool `json:"auto_complete" gorm:"not null;default:false"`
+	// Recurrence is an iCalendar RRULE; see RRule. Once the task is
+	// completed or past due, its next occurrence is created and takes the
+	// rule over.
+	Recurrence string `json:"recurrence" gorm:"size:255;not null;default:''"`
+	// RecurrenceOf is the task this one is the next occurrence of. The
+	// unique index lets a task have only one.
+	RecurrenceOf *uint           `json:"recurrence_of" gorm:"uniqueIndex"`
+	Checklist    []ChecklistItem `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Comments     []Comment       `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	// Progress is computed from the checklist; nil when it is empty.
+	Progress     *Progress `json:"progress,omitempty" gorm:"-"`
+	CommentCount int       `json:"comment_count" gorm:"-"`
+	// Version is incremented by every change to the task's own fields,
+	// guarding Update against lost updates; see ErrVersionConflict.
+	Version   uint      `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set while the task is in the trash. GORM leaves trashed
+	// tasks out of every query that is not Unscoped.
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// IsOverdue reports whether the task is still open past its due date.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// hasLabel reports whether a label with the given name is attached to the task.
+func (t *Task) hasLabel(name string) bool {
+	for _, label := range t.Labels {
+		if label.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ChecklistItem is one step of a task's checklist.
+type ChecklistItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint      `json:"-" gorm:"index;not null;default:0"`
+	TaskID      uint      `json:"task_id" gorm:"index;not null"`
+	Title       string    `json:"title"`
+	Done        bool      `json:"done"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Progress counts the done items of a checklist, shown as "3/5 done".
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// complete reports whether p describes a non-empty checklist with every
+// item done.
+func (p *Progress) complete() bool {
+	return p != nil && p.Total > 0 && p.Done == p.Total
+}
+
+// Comment is a message in the discussion thread of a task. AuthorID is
+// set for comments posted by an authenticated user, whose name is copied
+// to Author. EditedAt is set once the body has been changed.
+type Comment struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint       `json:"-" gorm:"index;not null;default:0"`
+	TaskID      uint       `json:"task_id" gorm:"index;not null"`
+	AuthorID    *uint      `json:"author_id" gorm:"index"`
+	Author      string     `json:"author" gorm:"size:100;not null"`
+	Body        string     `json:"body" gorm:"type:text;not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at"`
+}
+
+// Types of TaskEvent.
+const (
+	TaskCreated  = "task.created"
+	TaskUpdated  = "task.updated"
+	TaskDeleted  = "task.deleted"
+	TaskRestored = "task.restored"
+)
+
+// TaskEvent is an entry of the audit trail of a task: who changed which
+// of its fields, and when. Events are only ever appended, in the
+// transaction of the change they record, and outlive their task.
+// ActorID is nil for anonymous changes; RequestID is the X-Request-ID of
+// the request that made the change.
+type TaskEvent struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint          `json:"-" gorm:"index;not null;default:0"`
+	TaskID      uint          `json:"task_id" gorm:"index;not null"`
+	Type        string        `json:"type" gorm:"size:32;not null"`
+	ActorID     *uint         `json:"actor_id" gorm:"index"`
+	RequestID   string        `json:"request_id" gorm:"size:64;not null;default:''"`
+	Changes     []FieldChange `json:"changes" gorm:"type:text;serializer:json"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// FieldChange is the JSON value of a task field before and after a change.
+// A created task has no value before, and a deleted one none after.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// OutboxEvent is a task change waiting to be handed to the event sinks by
+// an OutboxRelay. It is written in the transaction of the change, so that
+// no committed change goes unannounced, and marked processed once every
+// sink has handled it, or once the relay gave up after repeated failures.
+type OutboxEvent struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint   `json:"-" gorm:"index;not null;default:0"`
+	Type        string `json:"type" gorm:"size:32;not null"`
+	// BoardIDs and Task are those of the HubEvent to relay.
+	BoardIDs    []uint     `json:"board_ids" gorm:"type:text;serializer:json"`
+	Task        *Task      `json:"task" gorm:"type:text;serializer:json"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	LastError   string     `json:"last_error" gorm:"size:500;not null;default:''"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at" gorm:"index"`
+}
+
+// Webhook subscribes an external URL to task events. Each delivery is
+// signed with Secret; see WebhookDispatcher.
+type Webhook struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint   `json:"-" gorm:"index;not null;default:0"`
+	URL         string `json:"url" gorm:"size:2048;not null"`
+	// Events lists the event types delivered to the webhook; empty means
+	// every type.
+	Events    []string  `json:"events" gorm:"type:text;serializer:json"`
+	Secret    string    `json:"-" gorm:"size:255;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Statuses of WebhookDelivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is an event queued for a webhook, with the outcome of
+// its latest attempt. Pending deliveries are attempted once NextAttemptAt
+// has passed, backing off after each failure until they run out of
+// attempts. ResponseStatus is zero when no response was received.
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	WorkspaceID    uint            `json:"-" gorm:"index;not null;default:0"`
+	WebhookID      uint            `json:"webhook_id" gorm:"index;not null"`
+	Webhook        *Webhook        `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Event          string          `json:"event" gorm:"size:32;not null"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:text;serializer:json"`
+	Status         string          `json:"status" gorm:"size:16;not null;index:idx_webhook_deliveries_due"`
+	Attempts       int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due"`
+	ResponseStatus int             `json:"response_status"`
+	LastError      string          `json:"last_error" gorm:"size:500;not null;default:''"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// Workspace is a tenant: a team whose users, boards, labels and tasks are
+// invisible to every other workspace. Models carrying a WorkspaceID are
+// scoped to the workspace of the request; see WithWorkspace.
+type Workspace struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// User is a member of the team that tasks can be assigned to. Emails are
+// unique within a workspace and stored in lower case, so the same person
+// may belong to several workspaces. Users without a password cannot log in.
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	WorkspaceID  uint      `json:"-" gorm:"not null;default:0;uniqueIndex:idx_users_workspace_email"`
+	Name         string    `json:"name" gorm:"size:100;not null"`
+	Email        string    `json:"email" gorm:"size:255;not null;uniqueIndex:idx_users_workspace_email"`
+	PasswordHash string    `json:"-" gorm:"size:60;not null;default:''"`        // bcrypt
+	Role         Role      `json:"role" gorm:"size:16;not null;default:editor"` // in the workspace
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// APIKey is a long-lived credential that lets scripts act on behalf of
+// its user. Only the SHA-256 hash of the key is stored; Prefix keeps its
+// first characters so that users can tell their keys apart. A key without
+// scopes has the same access as its user.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	User       *User      `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:16;not null"`
+	Hash       string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Scopes     ScopeList  `json:"scopes" gorm:"size:255;not null;default:''"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Label categorizes tasks, e.g. as bug, feature or chore. Names are unique
+// within a workspace.
+type Label struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint      `json:"-" gorm:"not null;default:0;uniqueIndex:idx_labels_workspace_name"`
+	Name        string    `json:"name" gorm:"size:50;not null;uniqueIndex:idx_labels_workspace_name"`
+	Color       string    `json:"color" gorm:"size:7;not null"` // #rrggbb
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Board is a kanban board made of ordered columns. Members are managed
+// through the MemberStore rather than saved with the board.
+type Board struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint          `json:"-" gorm:"index;not null;default:0"`
+	Name        string        `json:"name"`
+	Columns     []Column      `json:"columns,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	Members     []BoardMember `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// Role is the access level of a user on a board or in a workspace.
+type Role string
+
+// Roles, from least to most privileged. Viewers may read the board and its
+// tasks, editors may also change its tasks and columns, and owners may
+// also rename or delete the board and manage its members. In a workspace,
+// the role applies to the tasks that are not on a board.
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+// rank orders roles by privilege; unknown roles rank lowest.
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	}
+	return 0
+}
+
+// allows reports whether r grants at least the privileges of required.
+func (r Role) allows(required Role) bool {
+	return r.rank() >= required.rank()
+}
+
+// BoardMember grants a user a role on a board.
+type BoardMember struct {
+	BoardID     uint      `json:"board_id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey"`
+	WorkspaceID uint      `json:"-" gorm:"index;not null;default:0"`
+	User        *User     `json:"user,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	Role        Role      `json:"role" gorm:"size:16;not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Column is one list of a board. Tasks in a column flagged as Done are
+// reported as completed.
+type Column struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint      `json:"-" gorm:"index;not null;default:0"`
+	BoardID     uint      `json:"board_id" gorm:"index;not null"`
+	Name        string    `json:"name"`
+	Position    int       `json:"position"`
+	Done        bool      `json:"done"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName avoids the ambiguous "columns" table name.
+func (Column) TableName() string {
+	return "board_columns"
+}
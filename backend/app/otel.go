@@ -1,7 +1,8 @@
-package main
+package app
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"runtime"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -29,6 +31,7 @@ var (
 	requestSize        metric.Int64Histogram
 	responseSize       metric.Int64Histogram
 	activeRequests     metric.Int64UpDownCounter
+	connectedClients   metric.Int64UpDownCounter
 	
 	// Database metrics
 	dbOperations       metric.Int64Counter
@@ -44,7 +47,8 @@ var (
 	goroutineCount     metric.Int64UpDownCounter
 )
 
-func initTracer(ctx context.Context) func() {
+// InitTracer installs the global OTLP trace provider and returns its shutdown function.
+func InitTracer(ctx context.Context) func() {
 	endpoint := getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4317")
 	log.Printf("Attempting to connect to OTEL collector for tracing at: %s", endpoint)
 	
@@ -76,7 +80,8 @@ func initTracer(ctx context.Context) func() {
 	return func() { _ = tp.Shutdown(ctx) }
 }
 
-func initMetrics(ctx context.Context) func() {
+// InitMetrics installs the global OTLP meter provider and returns its shutdown function.
+func InitMetrics(ctx context.Context) func() {
 	endpoint := getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4317")
 	log.Printf("Attempting to connect to OTEL collector for metrics at: %s", endpoint)
 	
@@ -109,10 +114,6 @@ func initMetrics(ctx context.Context) func() {
 	)
 
 	otel.SetMeterProvider(mp)
-	meter = mp.Meter("taskboard-backend")
-	
-	// Initialize all metrics
-	initializeMetrics()
 	
 	// Start system metrics collection
 	go collectSystemMetrics(ctx)
@@ -121,6 +122,15 @@ func initMetrics(ctx context.Context) func() {
 	return func() { _ = mp.Shutdown(ctx) }
 }
 
+// The instruments are created from the global meter at package
+// initialization, so handlers can record metrics before InitMetrics runs
+// (or without it, as in tests). The global provider forwards them to the
+// SDK provider once InitMetrics installs it.
+func init() {
+	meter = otel.Meter("taskboard-backend")
+	initializeMetrics()
+}
+
 // Initialize all metrics instruments
 func initializeMetrics() {
 	var err error
@@ -171,6 +181,15 @@ func initializeMetrics() {
 		log.Fatalf("Failed to create active requests counter: %v", err)
 	}
 	
+	connectedClients, err = meter.Int64UpDownCounter(
+		"realtime_connected_clients",
+		metric.WithDescription("Number of clients connected to the event stream or WebSocket"),
+		metric.WithUnit("{client}"),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create connected clients counter: %v", err)
+	}
+	
 	// Database metrics
 	dbOperations, err = meter.Int64Counter(
 		"db_operations_total",
@@ -338,9 +357,16 @@ func MetricsMiddleware() gin.HandlerFunc {
 	}
 }
 
-// DatabaseMetricsMiddleware wraps database operations with metrics
-func TrackDBOperation(ctx context.Context, operation string, f func() error) error {
+// DatabaseMetricsMiddleware wraps database operations with metrics and a
+// span named after the operation. f runs with the span's context, so that
+// the queries it makes are children of the span. attrs are recorded on
+// the span.
+func TrackDBOperation(ctx context.Context, operation string, f func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
 	start := time.Now()
+	ctx, span := otel.Tracer("taskboard-backend").Start(ctx, "db."+operation,
+		trace.WithAttributes(append(attrs, attribute.String("operation", operation))...),
+	)
+	defer span.End()
 	
 	// Increment operation counter
 	dbOperations.Add(ctx, 1,
@@ -350,7 +376,10 @@ func TrackDBOperation(ctx context.Context, operation string, f func() error) err
 	)
 	
 	// Execute the operation
-	err := f()
+	err := f(ctx)
+	if err != nil {
+		span.RecordError(err)
+	}
 	
 	// Record duration
 	duration := time.Since(start).Seconds()
@@ -364,21 +393,63 @@ func TrackDBOperation(ctx context.Context, operation string, f func() error) err
 	return err
 }
 
-// UpdateTaskMetrics updates task-related metrics
-func UpdateTaskMetrics(ctx context.Context) {
+// UpdateTaskMetrics updates task-related metrics. When ctx is limited to a
+// workspace, only its tasks are counted and recorded under its ID.
+func UpdateTaskMetrics(ctx context.Context, store TaskStore) {
+	var opts []metric.AddOption
+	if workspaceID, ok := workspaceFrom(ctx); ok {
+		opts = append(opts, metric.WithAttributes(attribute.Int64("workspace_id", int64(workspaceID))))
+	}
+
 	// Count total tasks
-	var totalCount int64
-	DB.Model(&Task{}).Count(&totalCount)
+	totalCount, _ := store.Count(ctx, TaskFilter{})
 	
 	// Reset and update the task counter
-	taskCount.Add(ctx, -totalCount)
-	taskCount.Add(ctx, totalCount)
+	taskCount.Add(ctx, -totalCount, opts...)
+	taskCount.Add(ctx, totalCount, opts...)
 	
 	// Count completed tasks
-	var completedCount int64
-	DB.Model(&Task{}).Where("completed = ?", true).Count(&completedCount)
+	completed := true
+	completedCount, _ := store.Count(ctx, TaskFilter{Completed: &completed})
 	
 	// Reset and update the completed task counter
-	completedTaskCount.Add(ctx, -completedCount)
-	completedTaskCount.Add(ctx, completedCount)
-}
\ No newline at end of file
+	completedTaskCount.Add(ctx, -completedCount, opts...)
+	completedTaskCount.Add(ctx, completedCount, opts...)
+}
+
+// TrackDBConnections periodically records database connection pool stats.
+func TrackDBConnections(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			stats := db.Stats()
+
+			// Reset counter and set to current value
+			dbConnectionsOpen.Add(ctx, -int64(stats.OpenConnections))
+			dbConnectionsOpen.Add(ctx, int64(stats.OpenConnections))
+
+			// Additional DB stats as attributes
+			dbConnectionsOpen.Add(ctx, 0,
+				metric.WithAttributes(
+					attribute.Int("idle", stats.Idle),
+					attribute.Int("in_use", stats.InUse),
+					attribute.Int("max_open", stats.MaxOpenConnections),
+				),
+			)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// trackConnectedClient counts a client of the event stream or WebSocket
+// until the returned function is called.
+func trackConnectedClient(ctx context.Context, transport string) func() {
+	attrs := metric.WithAttributes(attribute.String("transport", transport))
+	connectedClients.Add(ctx, 1, attrs)
+	return func() { connectedClients.Add(context.WithoutCancel(ctx), -1, attrs) }
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// outboxMaxAttempts is the number of failed attempts after which the relay
+// gives up on an event, so that it does not hold up the ones after it.
+const outboxMaxAttempts = 10
+
+// OutboxConfig controls the outbox relay.
+type OutboxConfig struct {
+	// PollInterval is how often the relay looks for events it was not
+	// told about, such as those left behind by a crash.
+	PollInterval time.Duration
+	// Retention is how long processed events are kept.
+	Retention time.Duration
+}
+
+// LoadOutboxConfig reads the outbox settings from the environment.
+func LoadOutboxConfig() (OutboxConfig, error) {
+	var cfg OutboxConfig
+	var err error
+	if cfg.PollInterval, err = time.ParseDuration(getEnv("OUTBOX_POLL_INTERVAL", "1s")); err != nil || cfg.PollInterval <= 0 {
+		return cfg, errors.New("invalid OUTBOX_POLL_INTERVAL: expected a positive duration")
+	}
+	if cfg.Retention, err = time.ParseDuration(getEnv("OUTBOX_RETENTION", "24h")); err != nil {
+		return cfg, fmt.Errorf("invalid OUTBOX_RETENTION: %w", err)
+	}
+	return cfg, nil
+}
+
+// EventSink receives the task changes relayed from the outbox.
+type EventSink interface {
+	// Handle processes an event. ctx carries the transaction marking the
+	// event processed: sinks writing through the stores commit with the
+	// marker and handle each event exactly once, while the others may see
+	// an event again when relaying it fails afterwards.
+	Handle(ctx context.Context, event HubEvent) error
+}
+
+// HubSink publishes events to a Hub, streaming them to clients.
+type HubSink struct {
+	Hub *Hub
+}
+
+// Handle implements EventSink.
+func (s HubSink) Handle(_ context.Context, event HubEvent) error {
+	s.Hub.Publish(event)
+	return nil
+}
+
+// LogSink logs events.
+type LogSink struct{}
+
+// Handle implements EventSink.
+func (LogSink) Handle(_ context.Context, event HubEvent) error {
+	log.Printf("Event %s: task %d in workspace %d", event.Type, event.Task.ID, event.WorkspaceID)
+	return nil
+}
+
+// TaskMetricsSink refreshes the task gauges of the workspace of events.
+type TaskMetricsSink struct {
+	Tasks TaskStore
+}
+
+// Handle implements EventSink.
+func (s TaskMetricsSink) Handle(ctx context.Context, event HubEvent) error {
+	UpdateTaskMetrics(WithWorkspace(ctx, event.WorkspaceID), s.Tasks)
+	return nil
+}
+
+// OutboxRelay hands the events of an outbox to sinks, in order, and marks
+// them processed. Relays in several replicas may share an outbox: each
+// event is relayed by one of them.
+type OutboxRelay struct {
+	store OutboxStore
+	tx    Transactor
+	sinks []EventSink
+	wake  chan struct{}
+}
+
+// NewOutboxRelay returns a relay of the events in store to sinks, which
+// handle each event in the order given.
+func NewOutboxRelay(store OutboxStore, tx Transactor, sinks ...EventSink) *OutboxRelay {
+	return &OutboxRelay{store: store, tx: tx, sinks: sinks, wake: make(chan struct{}, 1)}
+}
+
+// Notify tells the relay that events were committed, so that it relays
+// them without waiting for the next poll.
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run relays events when notified and every poll interval, and purges
+// those processed for longer than the retention period, until ctx is done.
+func (r *OutboxRelay) Run(ctx context.Context, cfg OutboxConfig) {
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+	var lastPurge time.Time
+
+	for {
+		if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to relay outbox events: %v", err)
+		}
+		if time.Since(lastPurge) >= cfg.Retention {
+			r.purge(ctx, time.Now().Add(-cfg.Retention))
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-r.wake:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RelayPending relays the unprocessed events, oldest first, returning how
+// many it relayed. It stops at the first event that fails, to retry it
+// before relaying the ones after it.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	relayed := 0
+	for ctx.Err() == nil {
+		ok, err := r.relayNext(ctx)
+		if err != nil || !ok {
+			return relayed, err
+		}
+		relayed++
+	}
+	return relayed, ctx.Err()
+}
+
+// relayNext relays the oldest unprocessed event in a transaction,
+// returning false when there is none.
+func (r *OutboxRelay) relayNext(ctx context.Context) (bool, error) {
+	var claimed *OutboxEvent
+	err := r.tx.InTx(ctx, func(ctx context.Context) error {
+		err := TrackDBOperation(ctx, "claim_outbox_event", func(ctx context.Context) error {
+			var err error
+			claimed, err = r.store.ClaimNext(ctx)
+			return err
+		})
+		if err != nil || claimed == nil {
+			return err
+		}
+
+		event := HubEvent{Type: claimed.Type, WorkspaceID: claimed.WorkspaceID, BoardIDs: claimed.BoardIDs, Task: claimed.Task}
+		for _, sink := range r.sinks {
+			if err := sink.Handle(ctx, event); err != nil {
+				return fmt.Errorf("%T: %w", sink, err)
+			}
+		}
+		return TrackDBOperation(ctx, "mark_outbox_event", func(ctx context.Context) error {
+			return r.store.MarkProcessed(ctx, claimed.ID, time.Now())
+		})
+	})
+	if err == nil || claimed == nil {
+		return claimed != nil, err
+	}
+
+	giveUp := claimed.Attempts+1 >= outboxMaxAttempts
+	if giveUp {
+		log.Printf("Giving up on outbox event %d after %d attempts: %v", claimed.ID, outboxMaxAttempts, err)
+	}
+	recordErr := TrackDBOperation(ctx, "record_outbox_failure", func(ctx context.Context) error {
+		return r.store.RecordFailure(ctx, claimed.ID, err.Error(), giveUp)
+	})
+	return false, errors.Join(err, recordErr)
+}
+
+// purge removes the events processed before cutoff.
+func (r *OutboxRelay) purge(ctx context.Context, cutoff time.Time) {
+	var purged int64
+	err := TrackDBOperation(ctx, "purge_outbox", func(ctx context.Context) error {
+		var err error
+		purged, err = r.store.PurgeProcessed(ctx, cutoff)
+		return err
+	})
+	switch {
+	case err != nil:
+		log.Printf("Failed to purge outbox: %v", err)
+	case purged > 0:
+		log.Printf("Purged %d processed events from the outbox", purged)
+	}
+}
+
+// RunRelay relays the server's outbox until ctx is done; see OutboxRelay.
+// It returns right away when the stores have no outbox.
+func (s *Server) RunRelay(ctx context.Context, cfg OutboxConfig) {
+	if s.relay != nil {
+		s.relay.Run(ctx, cfg)
+	}
+}
+
+// appendOutbox writes event to the outbox in the transaction of ctx, to
+// be relayed once committed.
+func (s *Server) appendOutbox(ctx context.Context, event HubEvent) error {
+	outbox := OutboxEvent{Type: event.Type, WorkspaceID: event.WorkspaceID, BoardIDs: event.BoardIDs, Task: event.Task}
+	err := TrackDBOperation(ctx, "append_outbox_event", func(ctx context.Context) error {
+		return s.outbox.Append(ctx, &outbox)
+	})
+	if err == nil {
+		s.wakeRelay(ctx)
+	}
+	return err
+}
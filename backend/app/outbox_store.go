@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxStore persists the outbox of task changes. Events are appended in
+// the transaction of the change they announce; see Transactor.
+type OutboxStore interface {
+	// Append writes an event to the outbox, filling in its ID and time.
+	Append(ctx context.Context, event *OutboxEvent) error
+	// ClaimNext returns the oldest unprocessed event, or nil when there is
+	// none. Inside a transaction, the event stays claimed until it ends,
+	// and concurrent relays skip it.
+	ClaimNext(ctx context.Context) (*OutboxEvent, error)
+	// MarkProcessed records that an event was relayed.
+	MarkProcessed(ctx context.Context, id uint, at time.Time) error
+	// RecordFailure records a failed attempt at relaying an event, marking
+	// it processed when giveUp is set.
+	RecordFailure(ctx context.Context, id uint, message string, giveUp bool) error
+	// PurgeProcessed removes the events processed before cutoff, returning
+	// how many were removed.
+	PurgeProcessed(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// GormOutboxStore is an OutboxStore backed by a GORM database connection.
+type GormOutboxStore struct {
+	db *gorm.DB
+}
+
+// NewGormOutboxStore returns an OutboxStore that persists events through db.
+func NewGormOutboxStore(db *gorm.DB) *GormOutboxStore {
+	return &GormOutboxStore{db: db}
+}
+
+// Append inserts an event.
+func (s *GormOutboxStore) Append(ctx context.Context, event *OutboxEvent) error {
+	return dbFor(ctx, s.db).Create(event).Error
+}
+
+// ClaimNext selects the oldest unprocessed event. On PostgreSQL the row is
+// locked, skipping rows locked by other relays; SQLite already serializes
+// writers.
+func (s *GormOutboxStore) ClaimNext(ctx context.Context) (*OutboxEvent, error) {
+	db := dbFor(ctx, s.db)
+	query := db.Where("processed_at IS NULL").Order("id").Limit(1)
+	if db.Dialector.Name() == DriverPostgres {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+
+	var events []OutboxEvent
+	if err := query.Find(&events).Error; err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+// MarkProcessed sets the processed time of an event.
+func (s *GormOutboxStore) MarkProcessed(ctx context.Context, id uint, at time.Time) error {
+	return dbFor(ctx, s.db).Model(&OutboxEvent{}).Where("id = ?", id).UpdateColumn("processed_at", at).Error
+}
+
+// RecordFailure counts a failed attempt of an event.
+func (s *GormOutboxStore) RecordFailure(ctx context.Context, id uint, message string, giveUp bool) error {
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	updates := map[string]any{"attempts": gorm.Expr("attempts + 1"), "last_error": message}
+	if giveUp {
+		updates["processed_at"] = time.Now()
+	}
+	return dbFor(ctx, s.db).Model(&OutboxEvent{}).Where("id = ?", id).UpdateColumns(updates).Error
+}
+
+// PurgeProcessed deletes the events processed before cutoff.
+func (s *GormOutboxStore) PurgeProcessed(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := dbFor(ctx, s.db).Where("processed_at < ?", cutoff).Delete(&OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// cursorToken is the JSON payload of the opaque cursor query parameter.
+// It records the sort it was issued for so it cannot be replayed against
+// a different ordering.
+type cursorToken struct {
+	Sort  string     `json:"s"`
+	ID    uint       `json:"id"`
+	Time  *time.Time `json:"t,omitempty"`
+	Title *string    `json:"v,omitempty"`
+	Pos   *int       `json:"p,omitempty"`
+}
+
+// sortParam renders order in the syntax of the sort query parameter.
+func (s TaskSort) sortParam() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// encodeCursor returns the cursor pointing right after task under order.
+func encodeCursor(task *Task, order TaskSort) string {
+	token := cursorToken{Sort: order.sortParam(), ID: task.ID}
+	switch order.Field {
+	case SortUpdatedAt:
+		token.Time = &task.UpdatedAt
+	case SortTitle:
+		token.Title = &task.Title
+	case SortPosition:
+		token.Pos = &task.Position
+	default:
+		token.Time = &task.CreatedAt
+	}
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor previously produced by encodeCursor for
+// the same order.
+func decodeCursor(raw string, order TaskSort) (*TaskCursor, error) {
+	errInvalid := errors.New("invalid cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errInvalid
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.Sort != order.sortParam() {
+		return nil, errInvalid
+	}
+
+	cursor := &TaskCursor{ID: token.ID}
+	switch order.Field {
+	case SortTitle:
+		if token.Title == nil {
+			return nil, errInvalid
+		}
+		cursor.Title = *token.Title
+	case SortPosition:
+		if token.Pos == nil {
+			return nil, errInvalid
+		}
+		cursor.Position = *token.Pos
+	default:
+		if token.Time == nil {
+			return nil, errInvalid
+		}
+		cursor.CreatedAt = *token.Time
+		cursor.UpdatedAt = *token.Time
+	}
+	return cursor, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter.
+func parseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+// parseIDQuery parses an optional numeric ID query parameter.
+func parseIDQuery(c *gin.Context, name string) (*uint, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, strconv.IntSize)
+	if err != nil || id == 0 {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	value := uint(id)
+	return &value, nil
+}
+
+// parseTaskQuery reads the filter, sort and pagination parameters of
+// GET /api/tasks. Returned errors are safe to show to the client.
+func parseTaskQuery(c *gin.Context) (TaskFilter, ListOptions, error) {
+	var filter TaskFilter
+	opts := ListOptions{Limit: defaultPageSize}
+
+	if raw := c.Query("completed"); raw != "" {
+		completed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, opts, errors.New("invalid completed: expected true or false")
+		}
+		filter.Completed = &completed
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseTimeParam(c, "created_after"); err != nil {
+		return filter, opts, err
+	}
+	if filter.CreatedBefore, err = parseTimeParam(c, "created_before"); err != nil {
+		return filter, opts, err
+	}
+
+	if filter.BoardID, err = parseIDQuery(c, "board_id"); err != nil {
+		return filter, opts, err
+	}
+	if filter.ColumnID, err = parseIDQuery(c, "column_id"); err != nil {
+		return filter, opts, err
+	}
+
+	if raw := c.Query("priority"); raw != "" {
+		for _, p := range strings.Split(raw, ",") {
+			priority := Priority(strings.TrimSpace(p))
+			if !priority.valid() {
+				return filter, opts, errors.New("invalid priority: expected low, medium, high or urgent")
+			}
+			filter.Priorities = append(filter.Priorities, priority)
+		}
+	}
+
+	if filter.DueAfter, err = parseTimeParam(c, "due_after"); err != nil {
+		return filter, opts, err
+	}
+	if filter.DueBefore, err = parseTimeParam(c, "due_before"); err != nil {
+		return filter, opts, err
+	}
+
+	if raw := c.Query("overdue"); raw != "" {
+		overdue, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, opts, errors.New("invalid overdue: expected true or false")
+		}
+		filter.Overdue = &overdue
+	}
+
+	if raw := c.Query("recurring"); raw != "" {
+		recurring, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, opts, errors.New("invalid recurring: expected true or false")
+		}
+		filter.Recurring = &recurring
+	}
+
+	switch raw := c.Query("assignee"); raw {
+	case "":
+	case "none":
+		filter.Unassigned = true
+	case "me":
+		id, ok := currentUserID(c)
+		if !ok {
+			return filter, opts, errors.New("invalid assignee: me requires an authenticated user")
+		}
+		filter.AssigneeID = &id
+	default:
+		if filter.AssigneeID, err = parseIDQuery(c, "assignee"); err != nil {
+			return filter, opts, errors.New("invalid assignee: expected me, none or a user ID")
+		}
+	}
+
+	if raw := c.Query("label"); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				filter.Labels = append(filter.Labels, name)
+			}
+		}
+	}
+	switch c.DefaultQuery("label_match", "any") {
+	case "any":
+	case "all":
+		filter.AllLabels = true
+	default:
+		return filter, opts, errors.New("invalid label_match: expected any or all")
+	}
+
+	filter.Query = strings.TrimSpace(c.Query("q"))
+
+	opts.Sort = TaskSort{}.orDefault()
+	if raw := c.Query("sort"); raw != "" {
+		field, desc := strings.CutPrefix(raw, "-")
+		if !validSortField(field) {
+			return filter, opts, fmt.Errorf("invalid sort: expected one of %s, %s, %s or %s, optionally prefixed with -",
+				SortCreatedAt, SortUpdatedAt, SortTitle, SortPosition)
+		}
+		opts.Sort = TaskSort{Field: field, Desc: desc}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return filter, opts, fmt.Errorf("invalid limit: expected a number between 1 and %d", maxPageSize)
+		}
+		opts.Limit = limit
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		if opts.After, err = decodeCursor(raw, opts.Sort); err != nil {
+			return filter, opts, err
+		}
+	}
+
+	return filter, opts, nil
+}
+
+// eventCursorSort tags the cursors of the activity and delivery logs,
+// which are always ordered newest first.
+const eventCursorSort = "-id"
+
+// encodeEventCursor returns the cursor pointing right after the event
+// with the given ID.
+func encodeEventCursor(id uint) string {
+	data, _ := json.Marshal(cursorToken{Sort: eventCursorSort, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseEventQuery reads the pagination parameters of the activity and
+// delivery log routes. Returned errors are safe to show to the client.
+func parseEventQuery(c *gin.Context) (EventListOptions, error) {
+	opts := EventListOptions{Limit: defaultPageSize}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return opts, fmt.Errorf("invalid limit: expected a number between 1 and %d", maxPageSize)
+		}
+		opts.Limit = limit
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		var token cursorToken
+		data, err := base64.RawURLEncoding.DecodeString(raw)
+		if err == nil {
+			err = json.Unmarshal(data, &token)
+		}
+		if err != nil || token.Sort != eventCursorSort || token.ID == 0 {
+			return opts, errors.New("invalid cursor")
+		}
+		opts.Before = token.ID
+	}
+
+	return opts, nil
+}
+
+// setNextLink advertises the next page through an RFC 8288 Link header
+// that repeats the current query with the cursor replaced.
+func setNextLink(c *gin.Context, cursor string) {
+	query := c.Request.URL.Query()
+	query.Set("cursor", cursor)
+	c.Header("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, c.Request.URL.Path, query.Encode()))
+}
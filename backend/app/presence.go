@@ -0,0 +1,132 @@
+package app
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// PresenceUser is a user following a board over /api/ws.
+type PresenceUser struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	// Editing is the task the user is editing, if any.
+	Editing *uint `json:"editing"`
+}
+
+// Presence tracks the WebSocket clients following each board and the task
+// each of them is editing, and sends the users of a board to its clients
+// whenever they change.
+type Presence struct {
+	mu sync.Mutex
+	// boards maps board IDs to their clients and the task each edits.
+	boards map[uint]map[*wsClient]*uint
+}
+
+// NewPresence returns a Presence without clients.
+func NewPresence() *Presence {
+	return &Presence{boards: make(map[uint]map[*wsClient]*uint)}
+}
+
+// join makes client follow a board. Joining twice is not an error.
+func (p *Presence) join(boardID uint, client *wsClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clients, ok := p.boards[boardID]
+	if !ok {
+		clients = make(map[*wsClient]*uint)
+		p.boards[boardID] = clients
+	}
+	if _, ok := clients[client]; !ok {
+		clients[client] = nil
+		p.broadcast(boardID)
+	}
+}
+
+// edit sets the task client is editing on a board it follows, or clears
+// it when taskID is nil. It returns false when client does not follow the
+// board.
+func (p *Presence) edit(boardID uint, client *wsClient, taskID *uint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clients := p.boards[boardID]
+	if _, ok := clients[client]; !ok {
+		return false
+	}
+	clients[client] = taskID
+	p.broadcast(boardID)
+	return true
+}
+
+// leave stops client following a board.
+func (p *Presence) leave(boardID uint, client *wsClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.remove(boardID, client)
+}
+
+// leaveAll stops client following any board, once it disconnects.
+func (p *Presence) leaveAll(client *wsClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for boardID := range p.boards {
+		p.remove(boardID, client)
+	}
+}
+
+// following returns the first of boardIDs that client follows.
+func (p *Presence) following(client *wsClient, boardIDs []uint) (uint, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, boardID := range boardIDs {
+		if _, ok := p.boards[boardID][client]; ok {
+			return boardID, true
+		}
+	}
+	return 0, false
+}
+
+// remove is leave with p.mu held.
+func (p *Presence) remove(boardID uint, client *wsClient) {
+	clients := p.boards[boardID]
+	if _, ok := clients[client]; !ok {
+		return
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(p.boards, boardID)
+		return
+	}
+	p.broadcast(boardID)
+}
+
+// broadcast sends the users of a board to its clients; p.mu must be held.
+// A user connected more than once is listed once, editing the task set
+// by any of their connections.
+func (p *Presence) broadcast(boardID uint) {
+	byUser := make(map[uint]*PresenceUser)
+	for client, editing := range p.boards[boardID] {
+		user, ok := byUser[client.userID]
+		if !ok {
+			user = &PresenceUser{UserID: client.userID, Name: client.name}
+			byUser[client.userID] = user
+		}
+		if editing != nil {
+			user.Editing = editing
+		}
+	}
+
+	users := make([]PresenceUser, 0, len(byUser))
+	for _, user := range byUser {
+		users = append(users, *user)
+	}
+	slices.SortFunc(users, func(a, b PresenceUser) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	for client := range p.boards[boardID] {
+		client.enqueue(WSMessage{Type: wsPresence, BoardID: boardID, Users: users})
+	}
+}
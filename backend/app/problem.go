@@ -0,0 +1,96 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// problemContentType is the media type of Problem responses.
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object, the body of every error
+// response of the API. Problems carry no specific type, so Type is
+// "about:blank" and Title the standard text of Status; Detail explains
+// this occurrence.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the failed request.
+	Instance string `json:"instance,omitempty"`
+	// TraceID identifies the request's trace, for finding it in Tempo.
+	TraceID string `json:"trace_id,omitempty"`
+	// Errors lists the rejected fields of an invalid request body.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// newProblem returns the Problem for status with the given detail.
+func newProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Error implements error.
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Detail
+}
+
+// writeProblem ends the request with p, filling in its instance and
+// trace ID.
+func writeProblem(c *gin.Context, p *Problem) {
+	p.Instance = c.Request.URL.Path
+	if span := trace.SpanContextFromContext(c.Request.Context()); span.HasTraceID() {
+		p.TraceID = span.TraceID().String()
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		c.AbortWithStatus(p.Status)
+		return
+	}
+	c.Abort()
+	c.Data(p.Status, problemContentType, body)
+}
+
+// writeError ends the request with a Problem of the given status.
+func writeError(c *gin.Context, status int, detail string) {
+	writeProblem(c, newProblem(status, detail))
+}
+
+// writeInternalError ends the request with 500 for an unexpected err,
+// which is recorded on the request's span and logged rather than shown
+// to the client.
+func writeInternalError(c *gin.Context, err error, detail string) {
+	span := trace.SpanFromContext(c.Request.Context())
+	span.RecordError(err)
+	span.SetStatus(codes.Error, detail)
+	_ = c.Error(err)
+	writeError(c, http.StatusInternalServerError, detail)
+}
+
+// inputProblem is the 400 Problem for a request body that could not be
+// bound, listing the rejected fields when known.
+func inputProblem(err error) *Problem {
+	p := newProblem(http.StatusBadRequest, "invalid input")
+	if p.Errors = fieldErrors(err); p.Errors == nil {
+		p.Detail += ": " + err.Error()
+	}
+	return p
+}
+
+// writeInputError ends the request with the inputProblem for err.
+func writeInputError(c *gin.Context, err error) {
+	writeProblem(c, inputProblem(err))
+}
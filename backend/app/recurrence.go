@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// RecurrenceConfig controls the scheduler of recurring tasks.
+type RecurrenceConfig struct {
+	// Interval is how often the scheduler looks for recurring tasks that
+	// were completed or whose due date passed.
+	Interval time.Duration
+}
+
+// LoadRecurrenceConfig reads the recurrence settings from the environment.
+func LoadRecurrenceConfig() (RecurrenceConfig, error) {
+	var cfg RecurrenceConfig
+	var err error
+	if cfg.Interval, err = time.ParseDuration(getEnv("RECURRENCE_INTERVAL", "1m")); err != nil || cfg.Interval <= 0 {
+		return cfg, errors.New("invalid RECURRENCE_INTERVAL: expected a positive duration")
+	}
+	return cfg, nil
+}
+
+// RunScheduler creates the next occurrences of recurring tasks right away
+// and then every interval, until ctx is done. It schedules every
+// workspace.
+func (s *Server) RunScheduler(ctx context.Context, cfg RecurrenceConfig) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := s.ScheduleRecurrences(ctx)
+		if created > 0 {
+			log.Printf("Created %d occurrences of recurring tasks", created)
+		}
+		if err != nil {
+			log.Printf("Failed to schedule recurring tasks: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ScheduleRecurrences creates the next occurrence of every recurring task
+// that was completed or whose due date passed, returning how many it
+// created. The rule moves from each task to its next occurrence in one
+// transaction, guarded by the task's version: schedulers running
+// concurrently, or again after a restart, create each occurrence once.
+// A task that fails does not hold the others back: the errors of all
+// such tasks, each naming its task, are returned together.
+func (s *Server) ScheduleRecurrences(ctx context.Context) (int, error) {
+	recurring, done, overdue := true, true, true
+	var tasks []Task
+	for _, filter := range []TaskFilter{
+		{Recurring: &recurring, Completed: &done},
+		{Recurring: &recurring, Overdue: &overdue},
+	} {
+		err := TrackDBOperation(ctx, "list_recurring_tasks", func(ctx context.Context) error {
+			found, err := s.tasks.List(ctx, filter, ListOptions{})
+			tasks = append(tasks, found...)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	created := 0
+	var errs []error
+	for i := range tasks {
+		task := &tasks[i]
+		err := TrackDBOperation(ctx, "create_task_occurrence", func(ctx context.Context) error {
+			return s.inTx(WithWorkspace(ctx, task.WorkspaceID), func(ctx context.Context) error {
+				before := *task
+				rule := task.Recurrence
+				task.Recurrence = ""
+				if err := s.tasks.Update(ctx, task); err != nil {
+					return err
+				}
+				if err := s.recordTaskEvent(ctx, TaskUpdated, &before, task); err != nil {
+					return err
+				}
+				_, err := s.createOccurrence(ctx, task, rule, time.Now())
+				return err
+			})
+		})
+		switch {
+		case errors.Is(err, ErrVersionConflict), errors.Is(err, ErrTaskNotFound):
+			// Changed meanwhile, possibly by another scheduler: the next
+			// run sees the task as it is now.
+		case err != nil:
+			errs = append(errs, fmt.Errorf("task %d: %w", task.ID, err))
+		default:
+			created++
+		}
+	}
+	return created, errors.Join(errs...)
+}
+
+// createOccurrence creates the occurrence of rule following task, which
+// was completed or became due, and records its creation. The occurrence
+// is due at the first date of the rule after both the task's due date and
+// now, and copies the task's fields; it starts open, in the first open
+// column of the task's board. It returns nil when the rule has ended.
+func (s *Server) createOccurrence(ctx context.Context, task *Task, rule string, now time.Time) (*Task, error) {
+	rrule, err := ParseRRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if rrule.Count == 1 {
+		return nil, nil
+	}
+
+	anchor := now
+	if task.DueAt != nil {
+		anchor = *task.DueAt
+	}
+	due, ok := rrule.Next(anchor, now)
+	if !ok {
+		return nil, nil
+	}
+	if rrule.Count > 0 {
+		rrule.Count--
+		rule = rrule.String()
+	}
+
+	next := &Task{
+		Title:        task.Title,
+		Description:  task.Description,
+		Priority:     task.Priority,
+		DueAt:        &due,
+		BoardID:      task.BoardID,
+		Labels:       task.Labels,
+		AssigneeID:   task.AssigneeID,
+		AutoComplete: task.AutoComplete,
+		Recurrence:   rule,
+		RecurrenceOf: &task.ID,
+	}
+	if err := s.tasks.Create(ctx, next); err != nil {
+		return nil, err
+	}
+	if next, err = s.syncCompletedColumn(ctx, next); err != nil {
+		return nil, err
+	}
+	if err := s.recordTaskEvent(ctx, TaskCreated, nil, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
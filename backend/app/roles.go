@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizeBoard checks that the authenticated user holds at least the
+// required role on a board, aborting the request with 403 otherwise. Every
+// user may read a board; roles only restrict changes. Anonymous requests,
+// when authentication is disabled, are not restricted.
+func (s *Server) authorizeBoard(c *gin.Context, boardID uint, required Role) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return true
+	}
+
+	var problem *Problem
+	err := s.checkBoardRole(c.Request.Context(), userID, boardID, required)
+	switch {
+	case errors.As(err, &problem):
+		writeProblem(c, problem)
+	case err != nil:
+		writeInternalError(c, err, "failed to check board role")
+	}
+	return err == nil
+}
+
+// checkBoardRole returns a 403 Problem unless a user holds at least the
+// required role on a board.
+func (s *Server) checkBoardRole(ctx context.Context, userID, boardID uint, required Role) error {
+	if s.members == nil {
+		return nil
+	}
+
+	var role Role
+	err := TrackDBOperation(ctx, "find_board_role", func(ctx context.Context) error {
+		var err error
+		role, err = s.members.Role(ctx, boardID, userID)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+	if !role.allows(required) {
+		return newProblem(http.StatusForbidden, "this requires the "+string(required)+" role on the board")
+	}
+	return nil
+}
+
+// authorizeTask is authorizeBoard for a task on the given board, or
+// authorizeWorkspace when the task is not on a board.
+func (s *Server) authorizeTask(c *gin.Context, boardID *uint, required Role) bool {
+	if boardID != nil {
+		return s.authorizeBoard(c, *boardID, required)
+	}
+	return s.authorizeWorkspace(c, required)
+}
+
+// authorizeWorkspace checks that the authenticated user holds at least the
+// required role in their workspace, aborting the request with 403
+// otherwise. Anonymous requests are not restricted.
+func (s *Server) authorizeWorkspace(c *gin.Context, required Role) bool {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return true
+	}
+
+	var problem *Problem
+	err := s.checkWorkspaceRole(c.Request.Context(), userID, required)
+	switch {
+	case errors.As(err, &problem):
+		writeProblem(c, problem)
+	case err != nil:
+		writeInternalError(c, err, "failed to check workspace role")
+	}
+	return err == nil
+}
+
+// checkTaskRole is checkBoardRole for a task on the given board, or
+// checkWorkspaceRole when the task is not on a board.
+func (s *Server) checkTaskRole(ctx context.Context, userID uint, boardID *uint, required Role) error {
+	if boardID != nil {
+		return s.checkBoardRole(ctx, userID, *boardID, required)
+	}
+	return s.checkWorkspaceRole(ctx, userID, required)
+}
+
+// checkWorkspaceRole returns a 403 Problem unless a user exists and holds
+// at least the required role in their workspace.
+func (s *Server) checkWorkspaceRole(ctx context.Context, userID uint, required Role) error {
+	if s.users == nil {
+		return nil
+	}
+
+	var user *User
+	err := TrackDBOperation(ctx, "find_user", func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetUser(ctx, userID)
+		return err
+	})
+
+	// A deleted user holds no role, as on boards.
+	if errors.Is(err, ErrUserNotFound) {
+		return newProblem(http.StatusForbidden, "the user of this token no longer exists")
+	}
+	if err != nil {
+		return err
+	}
+	if !user.Role.allows(required) {
+		return newProblem(http.StatusForbidden, "this requires the "+string(required)+" role in the workspace")
+	}
+	return nil
+}
+
+// authorizeColumn is authorizeBoard for the board holding a column. A
+// missing column is reported with 404.
+func (s *Server) authorizeColumn(c *gin.Context, columnID uint, required Role) bool {
+	var column *Column
+	err := TrackDBOperation(c.Request.Context(), "find_column", func(ctx context.Context) error {
+		var err error
+		column, err = s.boards.FindColumn(ctx, columnID)
+		return err
+	})
+
+	if err != nil {
+		writeBoardError(c, err, "failed to check board role")
+		return false
+	}
+	return s.authorizeBoard(c, column.BoardID, required)
+}
+
+// requireBoardRole restricts a route under /boards/:id to users holding
+// at least the required role on that board.
+func (s *Server) requireBoardRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if boardID, ok := routeID(c, "id"); ok && !s.authorizeBoard(c, boardID, required) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireWorkspaceRole restricts a route to users holding at least the
+// required role in their workspace.
+func (s *Server) requireWorkspaceRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.authorizeWorkspace(c, required) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireTaskRole restricts a route under /tasks/:id to users holding at
+// least the required role on the task's board, or in the workspace for
+// tasks that are not on a board.
+func (s *Server) requireTaskRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		taskID, ok := routeID(c, "id")
+		if !ok {
+			c.Next()
+			return
+		}
+
+		var task *Task
+		err := TrackDBOperation(c.Request.Context(), "find_task", func(ctx context.Context) error {
+			var err error
+			task, err = s.tasks.Get(ctx, taskID)
+			return err
+		})
+
+		switch {
+		case errors.Is(err, ErrTaskNotFound):
+			// Let the handler report the missing task.
+		case err != nil:
+			writeInternalError(c, err, "failed to check board role")
+			return
+		case !s.authorizeTask(c, task.BoardID, required):
+			return
+		}
+		c.Next()
+	}
+}
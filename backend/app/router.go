@@ -0,0 +1,184 @@
+package app
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Router builds the Gin engine with all middleware and routes registered.
+func (s *Server) Router() *gin.Engine {
+	r := gin.Default()
+
+	// --- CORS middleware ---
+	frontendOrigin := "http://localhost"
+	if envOrigin := os.Getenv("FRONTEND_ORIGIN"); envOrigin != "" {
+		frontendOrigin = envOrigin
+	}
+
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:  []string{frontendOrigin},
+		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "X-API-Key", "If-Match", "If-None-Match", "X-Request-ID", "Last-Event-ID"},
+		ExposeHeaders: []string{"Content-Length", "Link", "ETag", "X-Request-ID"},
+		// Tokens travel in the Authorization header, not in cookies, so
+		// credentialed requests are not needed.
+		AllowCredentials: false,
+		MaxAge:           12 * time.Hour,
+	}))
+
+	// --- Tracing middleware ---
+	r.Use(otelgin.Middleware("taskboard-backend"))
+
+	// --- Metrics middleware (must come after instrument creation) ---
+	r.Use(MetricsMiddleware())
+
+	// --- Request IDs, recorded in the activity log ---
+	r.Use(requestID())
+
+	r.NoRoute(func(c *gin.Context) {
+		writeError(c, http.StatusNotFound, "no route matches "+c.Request.Method+" "+c.Request.URL.Path)
+	})
+
+	if s.auth != nil && s.users != nil && s.workspaces != nil {
+		auth := r.Group("/api/auth")
+		auth.POST("/register", s.register)
+		auth.POST("/login", s.login)
+		auth.POST("/refresh", s.refresh)
+	}
+
+	api := r.Group("/api")
+	if s.auth != nil {
+		api.Use(s.authenticate())
+	}
+
+	// Scope checks for API keys; see ScopeTasksRead.
+	read, write, full := requireScope(ScopeTasksRead), requireScope(ScopeTasksWrite), requireFullAccess()
+	// Board role checks; see authorizeBoard.
+	editTask := s.requireTaskRole(RoleEditor)
+	editBoard, ownBoard := s.requireBoardRole(RoleEditor), s.requireBoardRole(RoleOwner)
+	// Workspace role checks; see authorizeWorkspace.
+	editWorkspace, ownWorkspace := s.requireWorkspaceRole(RoleEditor), s.requireWorkspaceRole(RoleOwner)
+
+	{
+		api.GET("/tasks", read, s.getTasks)
+		api.GET("/tasks/search", read, s.searchTasks)
+		if s.tx != nil {
+			api.POST("/tasks/bulk", write, s.bulkTasks)
+		}
+		api.GET("/tasks/:id", read, s.getTask)
+		api.POST("/tasks", write, s.createTask)
+		api.PUT("/tasks/:id", write, editTask, s.updateTask)
+		api.PATCH("/tasks/:id", write, editTask, s.patchTask)
+		api.DELETE("/tasks/:id", write, editTask, s.deleteTask)
+		api.POST("/tasks/:id/restore", write, s.restoreTask)
+		api.GET("/trash", read, s.listTrash)
+		api.GET("/events", read, s.streamEvents)
+		api.GET("/ws", read, s.serveWS)
+	}
+
+	if s.activity != nil {
+		api.GET("/tasks/:id/history", read, s.taskHistory)
+		api.GET("/activity", read, s.listActivity)
+	}
+
+	if s.boards != nil {
+		api.POST("/tasks/:id/move", write, editTask, s.moveTask)
+
+		api.GET("/boards", read, s.listBoards)
+		api.POST("/boards", full, s.createBoard)
+		api.GET("/boards/:id", read, s.getBoard)
+		api.PUT("/boards/:id", full, ownBoard, s.updateBoard)
+		api.DELETE("/boards/:id", full, ownBoard, s.deleteBoard)
+
+		api.GET("/boards/:id/columns", read, s.listColumns)
+		api.POST("/boards/:id/columns", full, editBoard, s.createColumn)
+		api.PUT("/boards/:id/columns/:column_id", full, editBoard, s.updateColumn)
+		api.DELETE("/boards/:id/columns/:column_id", full, editBoard, s.deleteColumn)
+	}
+
+	if s.boards != nil && s.members != nil && s.users != nil {
+		api.GET("/boards/:id/members", read, s.listMembers)
+		api.POST("/boards/:id/members", full, ownBoard, s.addMember)
+		api.PUT("/boards/:id/members/:user_id", full, ownBoard, s.updateMember)
+		api.DELETE("/boards/:id/members/:user_id", full, ownBoard, s.removeMember)
+	}
+
+	if s.labels != nil {
+		api.POST("/tasks/:id/labels", write, editTask, s.attachLabels)
+		api.DELETE("/tasks/:id/labels/:label_id", write, editTask, s.detachLabel)
+
+		api.GET("/labels", read, s.listLabels)
+		api.POST("/labels", full, editWorkspace, s.createLabel)
+		api.GET("/labels/:id", read, s.getLabel)
+		api.PUT("/labels/:id", full, editWorkspace, s.updateLabel)
+		api.DELETE("/labels/:id", full, editWorkspace, s.deleteLabel)
+	}
+
+	if s.checklists != nil {
+		api.GET("/tasks/:id/checklist", read, s.listChecklistItems)
+		api.POST("/tasks/:id/checklist", write, editTask, s.createChecklistItem)
+		api.PUT("/tasks/:id/checklist/:item_id", write, editTask, s.updateChecklistItem)
+		api.DELETE("/tasks/:id/checklist/:item_id", write, editTask, s.deleteChecklistItem)
+	}
+
+	if s.comments != nil {
+		api.GET("/tasks/:id/comments", read, s.listComments)
+		api.POST("/tasks/:id/comments", write, editTask, s.createComment)
+		api.PUT("/tasks/:id/comments/:comment_id", write, editTask, s.updateComment)
+		api.DELETE("/tasks/:id/comments/:comment_id", write, editTask, s.deleteComment)
+	}
+
+	if s.users != nil {
+		api.PUT("/tasks/:id/assignee", write, editTask, s.assignTask)
+		api.DELETE("/tasks/:id/assignee", write, editTask, s.unassignTask)
+		api.POST("/tasks/:id/watchers", write, editTask, s.addWatcher)
+		api.DELETE("/tasks/:id/watchers/:user_id", write, editTask, s.removeWatcher)
+
+		api.GET("/me", full, s.getCurrentUser)
+		api.GET("/users", read, s.listUsers)
+		api.POST("/users", full, s.createUser)
+		api.GET("/users/:id", read, s.getUser)
+		api.PUT("/users/:id", full, s.updateUser)
+		api.DELETE("/users/:id", full, s.deleteUser)
+	}
+
+	if s.auth != nil && s.workspaces != nil {
+		api.GET("/workspace", read, s.getWorkspace)
+		api.PUT("/workspace", full, ownWorkspace, s.updateWorkspace)
+	}
+
+	if s.webhooks != nil {
+		api.GET("/webhooks", full, ownWorkspace, s.listWebhooks)
+		api.POST("/webhooks", full, ownWorkspace, s.createWebhook)
+		api.GET("/webhooks/:id", full, ownWorkspace, s.getWebhook)
+		api.DELETE("/webhooks/:id", full, ownWorkspace, s.deleteWebhook)
+		api.GET("/webhooks/:id/deliveries", full, ownWorkspace, s.listDeliveries)
+	}
+
+	if s.auth != nil && s.keys != nil {
+		api.GET("/keys", full, s.listAPIKeys)
+		api.POST("/keys", full, s.createAPIKey)
+		api.DELETE("/keys/:id", full, s.deleteAPIKey)
+	}
+
+	// Add debug endpoints to test metrics generation. They act on the
+	// caller's workspace like the API.
+	debug := r.Group("/debug")
+	if s.auth != nil {
+		debug.Use(s.authenticate(), full)
+	}
+	{
+		debug.GET("/metrics", s.debugMetrics)
+		debug.GET("/slow", s.debugSlow)
+		debug.GET("/stats", s.debugStats)
+		debug.POST("/generate-tasks", s.debugGenerateTasks)
+		debug.DELETE("/clear-tasks", s.debugClearTasks)
+	}
+
+	return r
+}
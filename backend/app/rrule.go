@@ -0,0 +1,331 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies of an RRule.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// rruleSearchDays bounds the search for the next occurrence, so that
+// rules that never match, such as the 30th of February, give up.
+const rruleSearchDays = 100 * 366
+
+// weekdayCodes are the iCalendar names of the days of the week.
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RRuleDay is a BYDAY entry: a day of the week, optionally restricted to
+// its Nth occurrence in the month, counting from the end when negative.
+type RRuleDay struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// RRule is an iCalendar recurrence rule (RFC 5545) such as
+// FREQ=WEEKLY;BYDAY=MO. It supports FREQ, INTERVAL, COUNT, UNTIL, BYDAY,
+// BYMONTHDAY, BYMONTH and WKST. The rule has no DTSTART of its own: the
+// occurrence it continues from takes its place.
+type RRule struct {
+	Freq     string
+	Interval int
+	// Count is the number of occurrences left, this one included; zero
+	// means unbounded.
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleDay
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// ParseRRule parses a recurrence rule, with or without its RRULE: prefix.
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	rule := &RRule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("malformed rule part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate %s", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(value)
+			if !slices.Contains([]string{FreqDaily, FreqWeekly, FreqMonthly, FreqYearly}, rule.Freq) {
+				err = errors.New("expected DAILY, WEEKLY, MONTHLY or YEARLY")
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseRRuleInt(value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseRRuleInt(value, 1, 10000)
+		case "UNTIL":
+			rule.Until, err = parseRRuleUntil(value)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				var d RRuleDay
+				if d, err = parseRRuleDay(day); err != nil {
+					break
+				}
+				rule.ByDay = append(rule.ByDay, d)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				var d int
+				if d, err = parseRRuleInt(day, -31, 31); err != nil || d == 0 {
+					err = errors.New("expected days between -31 and 31, except 0")
+					break
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, d)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(value, ",") {
+				var m int
+				if m, err = parseRRuleInt(month, 1, 12); err != nil {
+					break
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(m))
+			}
+		case "WKST":
+			var d RRuleDay
+			if d, err = parseRRuleDay(value); err == nil && d.N != 0 {
+				err = errors.New("expected a day of the week")
+			}
+			rule.WeekStart = d.Weekday
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, errors.New("missing FREQ")
+	case rule.Count > 0 && rule.Until != nil:
+		return nil, errors.New("COUNT and UNTIL are exclusive")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != FreqMonthly && (rule.Freq != FreqYearly || len(rule.ByMonth) == 0) {
+			return nil, errors.New("numbered BYDAY needs FREQ=MONTHLY, or FREQ=YEARLY with BYMONTH")
+		}
+		if day.N < -5 || day.N > 5 {
+			return nil, errors.New("invalid BYDAY: a month has at most 5 of each day")
+		}
+	}
+	return rule, nil
+}
+
+// parseRRuleInt parses an integer between lo and hi.
+func parseRRuleInt(s string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("expected a number between %d and %d", lo, hi)
+	}
+	return n, nil
+}
+
+// parseRRuleUntil parses an UNTIL date or UTC time. A date lasts until
+// the end of its day.
+func parseRRuleUntil(s string) (*time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", s); err == nil {
+		return &until, nil
+	}
+	until, err := time.Parse("20060102", s)
+	if err != nil {
+		return nil, errors.New("expected YYYYMMDD or YYYYMMDDTHHMMSSZ")
+	}
+	until = until.Add(24*time.Hour - time.Second)
+	return &until, nil
+}
+
+// parseRRuleDay parses a BYDAY entry such as MO, 1MO or -1FR.
+func parseRRuleDay(s string) (RRuleDay, error) {
+	s = strings.ToUpper(s)
+	if len(s) < 2 {
+		return RRuleDay{}, fmt.Errorf("malformed day %q", s)
+	}
+	day := RRuleDay{Weekday: -1}
+	for i, code := range weekdayCodes {
+		if code == s[len(s)-2:] {
+			day.Weekday = time.Weekday(i)
+		}
+	}
+	if day.Weekday < 0 {
+		return RRuleDay{}, fmt.Errorf("malformed day %q", s)
+	}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 {
+			return RRuleDay{}, fmt.Errorf("malformed day %q", s)
+		}
+		day.N = n
+	}
+	return day, nil
+}
+
+// String formats the rule, without the RRULE: prefix.
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = weekdayCodes[d.Weekday]
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after the given time, continuing from
+// the occurrence at anchor: it keeps the anchor's time of day and zone,
+// counts intervals from it and, for the parts the rule leaves out, repeats
+// its day of the week, month or year. It returns false when the rule has
+// no further occurrence.
+func (r *RRule) Next(anchor, after time.Time) (time.Time, bool) {
+	if after.Before(anchor) {
+		after = anchor
+	}
+	loc := anchor.Location()
+	from := after.In(loc)
+	for i := 0; i <= rruleSearchDays; i++ {
+		day := time.Date(from.Year(), from.Month(), from.Day()+i, anchor.Hour(), anchor.Minute(), anchor.Second(), 0, loc)
+		if !day.After(after) || !r.inInterval(anchor, day) || !r.matches(anchor, day) {
+			continue
+		}
+		if r.Until != nil && day.After(*r.Until) {
+			return time.Time{}, false
+		}
+		return day, true
+	}
+	return time.Time{}, false
+}
+
+// inInterval reports whether day falls in a period, of the rule's
+// frequency, a multiple of the interval away from the anchor's.
+func (r *RRule) inInterval(anchor, day time.Time) bool {
+	var periods int
+	switch r.Freq {
+	case FreqDaily:
+		periods = civilDays(anchor, day)
+	case FreqWeekly:
+		offset := func(t time.Time) int { return (int(t.Weekday()) - int(r.WeekStart) + 7) % 7 }
+		periods = (civilDays(anchor, day) + offset(anchor) - offset(day)) / 7
+	case FreqMonthly:
+		periods = (day.Year()-anchor.Year())*12 + int(day.Month()) - int(anchor.Month())
+	case FreqYearly:
+		periods = day.Year() - anchor.Year()
+	}
+	return periods%r.Interval == 0
+}
+
+// civilDays returns the number of calendar days from a to b.
+func civilDays(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// matches reports whether the rule selects day.
+func (r *RRule) matches(anchor, day time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, day.Month()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !slices.ContainsFunc(r.ByMonthDay, func(d int) bool { return monthDayMatches(d, day) }) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !slices.ContainsFunc(r.ByDay, func(d RRuleDay) bool { return d.matches(day) }) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+		return true
+	}
+
+	switch r.Freq {
+	case FreqWeekly:
+		return day.Weekday() == anchor.Weekday()
+	case FreqMonthly:
+		return day.Day() == anchor.Day()
+	case FreqYearly:
+		return day.Day() == anchor.Day() && (len(r.ByMonth) > 0 || day.Month() == anchor.Month())
+	}
+	return true
+}
+
+// monthDayMatches reports whether day is the nth of its month, counting
+// from the end when n is negative.
+func monthDayMatches(n int, day time.Time) bool {
+	if n > 0 {
+		return day.Day() == n
+	}
+	return day.Day() == daysInMonth(day)+1+n
+}
+
+// matches reports whether day is the BYDAY entry's day of the week and,
+// when numbered, its Nth occurrence in the month.
+func (d RRuleDay) matches(day time.Time) bool {
+	switch {
+	case day.Weekday() != d.Weekday:
+		return false
+	case d.N > 0:
+		return (day.Day()-1)/7+1 == d.N
+	case d.N < 0:
+		return (daysInMonth(day)-day.Day())/7+1 == -d.N
+	}
+	return true
+}
+
+// daysInMonth returns the number of days of the month of t.
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
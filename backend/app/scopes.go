@@ -0,0 +1,77 @@
+package app
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scopes that restrict what an API key may do. tasks:read covers reading
+// tasks and what is attached to them (checklists, comments, labels,
+// boards); tasks:write covers changing them. Managing boards, labels,
+// users and keys requires a credential without scopes.
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+)
+
+// ScopeList is a set of scopes stored as a space-separated string.
+type ScopeList []string
+
+// Value implements driver.Valuer.
+func (l ScopeList) Value() (driver.Value, error) {
+	return strings.Join(l, " "), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *ScopeList) Scan(src any) error {
+	var raw string
+	switch v := src.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into ScopeList", src)
+	}
+	*l = strings.Fields(raw)
+	return nil
+}
+
+// allows reports whether a credential limited to l may use scope. An
+// empty list is unrestricted; an empty scope is only allowed to
+// unrestricted credentials.
+func (l ScopeList) allows(scope string) bool {
+	if len(l) == 0 {
+		return true
+	}
+	return scope != "" && slices.Contains(l, scope)
+}
+
+// requireScope rejects requests whose credential is not allowed to use
+// scope with 403. Anonymous requests, when authentication is disabled,
+// are not restricted.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := currentIdentity(c)
+		if ok && !identity.Scopes.allows(scope) {
+			message := "this API key is not allowed to use this route"
+			if scope != "" {
+				message = "this API key lacks the " + scope + " scope"
+			}
+			writeError(c, http.StatusForbidden, message)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireFullAccess only lets unrestricted credentials through.
+func requireFullAccess() gin.HandlerFunc {
+	return requireScope("")
+}
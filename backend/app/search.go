@@ -0,0 +1,180 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"html"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// searchCandidates bounds the tasks ranked by substring search.
+	searchCandidates = 1000
+)
+
+// searchTerms splits a search query into the words that must all match.
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// termMatcher finds occurrences of search terms, ignoring case.
+type termMatcher struct {
+	terms []*regexp.Regexp
+	any   *regexp.Regexp
+}
+
+// newTermMatcher compiles matchers for terms.
+func newTermMatcher(terms []string) *termMatcher {
+	m := &termMatcher{}
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		q := regexp.QuoteMeta(term)
+		quoted = append(quoted, q)
+		m.terms = append(m.terms, regexp.MustCompile("(?i)"+q))
+	}
+	m.any = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	return m
+}
+
+// snippetRadius is the number of bytes of context kept on each side of
+// the first hit in a description snippet.
+const snippetRadius = 80
+
+// match reports whether the task's title and description together contain
+// every term. On a match it returns the task's rank, the share of words
+// that are hits with title hits counting double, and its highlighted title
+// and description snippet, mirroring ts_rank and ts_headline.
+func (m *termMatcher) match(task *Task) (SearchResult, bool) {
+	text := task.Title + "\n" + task.Description
+	for _, term := range m.terms {
+		if !term.MatchString(text) {
+			return SearchResult{}, false
+		}
+	}
+
+	titleHits := len(m.any.FindAllStringIndex(task.Title, -1))
+	descriptionHits := len(m.any.FindAllStringIndex(task.Description, -1))
+	words := max(len(strings.Fields(text)), 1)
+
+	return SearchResult{
+		Task:      *task,
+		Rank:      float64(2*titleHits+descriptionHits) / float64(words),
+		Highlight: m.highlight(task.Title),
+		Snippet:   m.snippet(task.Description),
+	}, true
+}
+
+// highlight escapes text as HTML and wraps every hit in <mark> tags.
+func (m *termMatcher) highlight(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range m.any.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[loc[0]:loc[1]]) + "</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// Delimiters of the hits marked by ts_headline, which cannot be told
+// apart from the task's text until that is escaped; see markHeadline.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// headlineMarks replaces the delimiters of ts_headline hits in escaped
+// text with <mark> tags.
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// markHeadline escapes a ts_headline result as HTML and wraps its hits in
+// <mark> tags.
+func markHeadline(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+// snippet returns the part of text surrounding its first hit, or its
+// beginning when nothing matches, with hits highlighted.
+func (m *termMatcher) snippet(text string) string {
+	start := 0
+	if loc := m.any.FindStringIndex(text); loc != nil {
+		start = max(loc[0]-snippetRadius, 0)
+	}
+	end := min(start+2*snippetRadius, len(text))
+
+	// Keep the cut on rune boundaries.
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	excerpt := m.highlight(text[start:end])
+	if start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(text) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// rankResults orders results by decreasing rank, newest first on ties,
+// and truncates them to limit.
+func rankResults(results []SearchResult, limit int) []SearchResult {
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// searchTasks returns the tasks matching the q query parameter, most
+// relevant first, with the matched fragments highlighted.
+func (s *Server) searchTasks(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		writeError(c, http.StatusBadRequest, "missing q")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			writeError(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	var results []SearchResult
+	err := TrackDBOperation(c.Request.Context(), "search_tasks", func(ctx context.Context) error {
+		var err error
+		results, err = s.tasks.Search(ctx, query, limit)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to search tasks")
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTaskNotFound is returned by a TaskStore when no task matches the
+// requested ID.
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrVersionConflict is returned by TaskStore.Update when the task was
+// changed since it was read.
+var ErrVersionConflict = errors.New("task was changed by another request")
+
+// TaskFilter narrows the set of tasks returned by List and Count.
+// A nil or empty field means the filter is not applied.
+type TaskFilter struct {
+	Completed     *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	BoardID       *uint
+	ColumnID      *uint
+	// Priorities matches tasks having any of the listed priorities.
+	Priorities []Priority
+	DueAfter   *time.Time
+	DueBefore  *time.Time
+	// Overdue matches open tasks whose due date has passed (true) or
+	// every other task (false).
+	Overdue *bool
+	// Recurring matches tasks with a recurrence rule (true) or without
+	// one (false).
+	Recurring *bool
+	// AssigneeID matches tasks assigned to the user; Unassigned matches
+	// tasks without an assignee.
+	AssigneeID *uint
+	Unassigned bool
+	// Labels matches tasks carrying any of the named labels, or all of
+	// them when AllLabels is set.
+	Labels    []string
+	AllLabels bool
+	// Query matches tasks whose title contains it, ignoring case.
+	Query string
+}
+
+// Fields that List results can be sorted by.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortTitle     = "title"
+	SortPosition  = "position"
+)
+
+// errUnsupportedSort is returned by List for an unknown TaskSort field.
+var errUnsupportedSort = errors.New("unsupported sort field")
+
+// validSortField reports whether List can order tasks by field.
+func validSortField(field string) bool {
+	switch field {
+	case SortCreatedAt, SortUpdatedAt, SortTitle, SortPosition:
+		return true
+	}
+	return false
+}
+
+// TaskSort selects the ordering of List results. Ties are broken by ID
+// in the same direction so that the order is total.
+type TaskSort struct {
+	Field string
+	Desc  bool
+}
+
+// orDefault returns s, or newest-first ordering when no field is set.
+func (s TaskSort) orDefault() TaskSort {
+	if s.Field == "" {
+		return TaskSort{Field: SortCreatedAt, Desc: true}
+	}
+	return s
+}
+
+// TaskCursor identifies the last task of a previous page. Only the field
+// matching the sort in use is meaningful besides ID.
+type TaskCursor struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Title     string
+	Position  int
+}
+
+// ListOptions controls ordering and pagination of List.
+type ListOptions struct {
+	Sort TaskSort
+	// Limit caps the number of returned tasks; zero means no limit.
+	Limit int
+	// After resumes the listing right after the given position.
+	After *TaskCursor
+}
+
+// SearchResult is a task matched by a full-text search, with its relevance.
+// Highlight is the title and Snippet an excerpt of the description, both
+// HTML-escaped and with the matched words wrapped in <mark> tags.
+type SearchResult struct {
+	Task
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+	Snippet   string  `json:"snippet"`
+}
+
+// TaskStore abstracts task persistence so handlers do not depend on a
+// concrete database and can be exercised against an in-memory backend.
+type TaskStore interface {
+	// List returns the tasks matching filter ordered and paginated
+	// according to opts.
+	List(ctx context.Context, filter TaskFilter, opts ListOptions) ([]Task, error)
+	// Get returns the task with the given ID or ErrTaskNotFound.
+	Get(ctx context.Context, id uint) (*Task, error)
+	// Create persists a new task and fills in its ID and timestamps.
+	Create(ctx context.Context, task *Task) error
+	// Update saves all fields of an existing task and increments its
+	// Version. It fails with ErrVersionConflict unless task.Version is
+	// still the stored version.
+	Update(ctx context.Context, task *Task) error
+	// Delete moves the task with the given ID to the trash, where it is
+	// hidden from every other method until restored or purged, and moves
+	// the tasks below it in its column up. Deleting a task that does not
+	// exist is not an error.
+	Delete(ctx context.Context, id uint) error
+	// DeleteAll moves every task to the trash.
+	DeleteAll(ctx context.Context) error
+	// ListDeleted returns the tasks in the trash, most recently deleted
+	// first.
+	ListDeleted(ctx context.Context) ([]Task, error)
+	// GetDeleted returns the task with the given ID from the trash or
+	// ErrTaskNotFound.
+	GetDeleted(ctx context.Context, id uint) (*Task, error)
+	// Restore takes the task with the given ID out of the trash, or fails
+	// with ErrTaskNotFound. A task goes back to the bottom of its column,
+	// or off its board when the column was removed meanwhile.
+	Restore(ctx context.Context, id uint) (*Task, error)
+	// Purge permanently removes the tasks moved to the trash before
+	// cutoff and returns how many it removed.
+	Purge(ctx context.Context, cutoff time.Time) (int64, error)
+	// Count returns the number of tasks matching filter.
+	Count(ctx context.Context, filter TaskFilter) (int64, error)
+	// Search returns up to limit tasks matching the full-text query,
+	// most relevant first.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
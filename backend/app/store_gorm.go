@@ -0,0 +1,465 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormTaskStore is a TaskStore backed by a GORM database connection.
+type GormTaskStore struct {
+	db *gorm.DB
+}
+
+// NewGormTaskStore returns a TaskStore that persists tasks through db.
+func NewGormTaskStore(db *gorm.DB) *GormTaskStore {
+	return &GormTaskStore{db: db}
+}
+
+// likeEscaper escapes the LIKE wildcards in user-supplied search text.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns a LIKE pattern matching values that contain s.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(s)) + "%"
+}
+
+// applyFilter restricts query to the tasks matching filter.
+func applyFilter(query *gorm.DB, filter TaskFilter) *gorm.DB {
+	if filter.Completed != nil {
+		query = query.Where("completed = ?", *filter.Completed)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.BoardID != nil {
+		query = query.Where("board_id = ?", *filter.BoardID)
+	}
+	if filter.ColumnID != nil {
+		query = query.Where("column_id = ?", *filter.ColumnID)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("priority IN ?", filter.Priorities)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("due_at > ?", *filter.DueAfter)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_at < ?", *filter.DueBefore)
+	}
+	if filter.Overdue != nil {
+		overdue := "completed = ? AND due_at IS NOT NULL AND due_at < ?"
+		if *filter.Overdue {
+			query = query.Where(overdue, false, time.Now())
+		} else {
+			query = query.Where("NOT ("+overdue+")", false, time.Now())
+		}
+	}
+	if filter.Recurring != nil {
+		if *filter.Recurring {
+			query = query.Where("recurrence <> ''")
+		} else {
+			query = query.Where("recurrence = ''")
+		}
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("assignee_id = ?", *filter.AssigneeID)
+	}
+	if filter.Unassigned {
+		query = query.Where("assignee_id IS NULL")
+	}
+	if len(filter.Labels) > 0 {
+		labeled := query.Session(&gorm.Session{NewDB: true}).Table("task_labels").
+			Select("task_labels.task_id").
+			Joins("JOIN labels ON labels.id = task_labels.label_id").
+			Where("labels.name IN ?", filter.Labels)
+		if filter.AllLabels {
+			distinct := slices.Compact(slices.Sorted(slices.Values(filter.Labels)))
+			labeled = labeled.Group("task_labels.task_id").Having("COUNT(DISTINCT labels.id) = ?", len(distinct))
+		}
+		query = query.Where("id IN (?)", labeled)
+	}
+	if filter.Query != "" {
+		query = query.Where(`LOWER(title) LIKE ? ESCAPE '\'`, containsPattern(filter.Query))
+	}
+	return query
+}
+
+// sortValue returns the cursor value for the given sort field.
+func (c *TaskCursor) sortValue(field string) any {
+	switch field {
+	case SortUpdatedAt:
+		return c.UpdatedAt
+	case SortTitle:
+		return c.Title
+	case SortPosition:
+		return c.Position
+	default:
+		return c.CreatedAt
+	}
+}
+
+// List returns the tasks matching filter in the order and page selected by opts.
+func (s *GormTaskStore) List(ctx context.Context, filter TaskFilter, opts ListOptions) ([]Task, error) {
+	order := opts.Sort.orDefault()
+	if !validSortField(order.Field) {
+		return nil, fmt.Errorf("%w %q", errUnsupportedSort, order.Field)
+	}
+
+	direction, comparison := "asc", ">"
+	if order.Desc {
+		direction, comparison = "desc", "<"
+	}
+
+	query := applyFilter(dbFor(ctx, s.db).Model(&Task{}), filter)
+
+	// Keyset pagination: continue strictly after the cursor position.
+	if opts.After != nil {
+		value := opts.After.sortValue(order.Field)
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", order.Field, comparison),
+			value, value, opts.After.ID,
+		)
+	}
+
+	query = query.Order(fmt.Sprintf("%[1]s %[2]s, id %[2]s", order.Field, direction))
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	var tasks []Task
+	if err := preloadTaskAssociations(query).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := loadTaskDetails(dbFor(ctx, s.db), tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// preloadTaskAssociations loads the labels, assignee and watchers of the
+// queried tasks.
+func preloadTaskAssociations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Labels", orderedLabels).Preload("Assignee").Preload("Watchers", orderedUsers)
+}
+
+// loadTaskDetails fills in the checklist progress and comment count of
+// tasks with one aggregate query each.
+func loadTaskDetails(db *gorm.DB, tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+
+	var progress []struct {
+		TaskID uint
+		Done   int
+		Total  int
+	}
+	err := db.Model(&ChecklistItem{}).
+		Select("task_id, SUM(CASE WHEN done THEN 1 ELSE 0 END) AS done, COUNT(*) AS total").
+		Where("task_id IN ?", ids).Group("task_id").Scan(&progress).Error
+	if err != nil {
+		return err
+	}
+
+	var comments []struct {
+		TaskID uint
+		Count  int
+	}
+	err = db.Model(&Comment{}).Select("task_id, COUNT(*) AS count").
+		Where("task_id IN ?", ids).Group("task_id").Scan(&comments).Error
+	if err != nil {
+		return err
+	}
+
+	index := make(map[uint]*Task, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = &tasks[i]
+	}
+	for _, row := range progress {
+		index[row.TaskID].Progress = &Progress{Done: row.Done, Total: row.Total}
+	}
+	for _, row := range comments {
+		index[row.TaskID].CommentCount = row.Count
+	}
+	return nil
+}
+
+// findTask loads a task with its associations, checklist progress and
+// comment count through db, which may be a transaction.
+func findTask(db *gorm.DB, id uint) (*Task, error) {
+	var task Task
+	err := preloadTaskAssociations(db).First(&task, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	tasks := []Task{task}
+	if err := loadTaskDetails(db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// Get returns the task with the given ID.
+func (s *GormTaskStore) Get(ctx context.Context, id uint) (*Task, error) {
+	return findTask(dbFor(ctx, s.db), id)
+}
+
+// Create inserts a new task.
+func (s *GormTaskStore) Create(ctx context.Context, task *Task) error {
+	return dbFor(ctx, s.db).Create(task).Error
+}
+
+// nextVersion increments the Version of the tasks changed by a bulk update.
+var nextVersion = gorm.Expr("version + 1")
+
+// Update saves all fields of an existing task if its version is
+// unchanged. Its labels are left untouched.
+func (s *GormTaskStore) Update(ctx context.Context, task *Task) error {
+	db := dbFor(ctx, s.db)
+	version := task.Version
+	task.Version++
+	res := db.Model(task).Select("*").Omit(clause.Associations).Where("version = ?", version).Updates(task)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+
+	task.Version = version
+	if err := taskExists(db, task.ID); err != nil {
+		return err
+	}
+	return ErrVersionConflict
+}
+
+// Delete moves the task with the given ID to the trash by setting its
+// deleted_at column, and closes the gap it leaves in its column.
+func (s *GormTaskStore) Delete(ctx context.Context, id uint) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockTaskPositions(tx); err != nil {
+			return err
+		}
+
+		var tasks []Task
+		if err := tx.Limit(1).Find(&tasks, id).Error; err != nil || len(tasks) == 0 {
+			return err
+		}
+		task := tasks[0]
+		if err := tx.Delete(&Task{}, id).Error; err != nil {
+			return err
+		}
+		if task.ColumnID == nil {
+			return nil
+		}
+		return tx.Model(&Task{}).
+			Where("column_id = ? AND position > ?", *task.ColumnID, task.Position).
+			Updates(map[string]any{"position": gorm.Expr("position - 1"), "version": nextVersion}).Error
+	})
+}
+
+// DeleteAll moves every task to the trash.
+func (s *GormTaskStore) DeleteAll(ctx context.Context) error {
+	return dbFor(ctx, s.db).Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&Task{}).Error
+}
+
+// trashed selects the tasks in the trash through db.
+func trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("tasks.deleted_at IS NOT NULL")
+}
+
+// ListDeleted returns the tasks in the trash, most recently deleted first.
+func (s *GormTaskStore) ListDeleted(ctx context.Context) ([]Task, error) {
+	db := dbFor(ctx, s.db)
+	tasks := []Task{}
+	err := preloadTaskAssociations(trashed(db)).Order("deleted_at desc, id desc").Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTaskDetails(db, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// findDeletedTask is findTask for a task in the trash.
+func findDeletedTask(db *gorm.DB, id uint) (*Task, error) {
+	var task Task
+	err := preloadTaskAssociations(trashed(db)).First(&task, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	tasks := []Task{task}
+	if err := loadTaskDetails(db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// GetDeleted returns the task with the given ID from the trash.
+func (s *GormTaskStore) GetDeleted(ctx context.Context, id uint) (*Task, error) {
+	return findDeletedTask(dbFor(ctx, s.db), id)
+}
+
+// Restore takes a task out of the trash. Like MoveTask, it places the task
+// at the bottom of its column and completes it when the column is a done
+// column.
+func (s *GormTaskStore) Restore(ctx context.Context, id uint) (*Task, error) {
+	var task *Task
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockTaskPositions(tx); err != nil {
+			return err
+		}
+
+		deleted, err := findDeletedTask(tx, id)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{"deleted_at": nil, "version": nextVersion}
+		if deleted.ColumnID != nil {
+			var column Column
+			res := tx.Limit(1).Find(&column, *deleted.ColumnID)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				updates["board_id"], updates["column_id"], updates["position"] = nil, nil, 0
+			} else {
+				// The slot after the last task is free whatever gaps the
+				// column has.
+				var last *int
+				err := tx.Model(&Task{}).Where("column_id = ?", column.ID).Select("MAX(position)").Scan(&last).Error
+				if err != nil {
+					return err
+				}
+				position := 0
+				if last != nil {
+					position = *last + 1
+				}
+				updates["position"], updates["completed"] = position, column.Done
+			}
+		}
+
+		if err := tx.Unscoped().Model(&Task{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		task, err = findTask(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Purge permanently removes the tasks trashed before cutoff. Their
+// checklist items, comments, labels and watchers go with them through
+// the foreign keys' ON DELETE CASCADE.
+func (s *GormTaskStore) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := trashed(dbFor(ctx, s.db)).Where("tasks.deleted_at < ?", cutoff).Delete(&Task{})
+	return res.RowsAffected, res.Error
+}
+
+// Count returns the number of tasks matching filter.
+func (s *GormTaskStore) Count(ctx context.Context, filter TaskFilter) (int64, error) {
+	var count int64
+	err := applyFilter(dbFor(ctx, s.db).Model(&Task{}), filter).Count(&count).Error
+	return count, err
+}
+
+// taskSearchVector is the full-text document of a task in PostgreSQL,
+// weighting title matches above description matches. The GIN index
+// created by OpenDB is built on the same expression so that searches can
+// use it.
+const taskSearchVector = "(setweight(to_tsvector('english', title), 'A') || " +
+	"setweight(to_tsvector('english', description), 'B'))"
+
+// Search ranks tasks against query using PostgreSQL full-text search, or
+// substring matching of the newest searchCandidates tasks on other
+// databases.
+func (s *GormTaskStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	if s.db.Dialector.Name() == DriverPostgres {
+		return s.searchFullText(ctx, query, limit)
+	}
+
+	db := dbFor(ctx, s.db).Model(&Task{})
+	for _, term := range terms {
+		pattern := containsPattern(term)
+		db = db.Where(`(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(description) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+
+	// Rank the newest candidates only, rather than reading every task of
+	// the workspace for a short query.
+	var tasks []Task
+	if err := db.Order("id DESC").Limit(searchCandidates).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	matcher := newTermMatcher(terms)
+	results := make([]SearchResult, 0, len(tasks))
+	for i := range tasks {
+		if result, ok := matcher.match(&tasks[i]); ok {
+			results = append(results, result)
+		}
+	}
+	return rankResults(results, limit), nil
+}
+
+// searchFullText runs query through websearch_to_tsquery, ranking matches
+// with ts_rank and highlighting them with ts_headline. Being raw SQL, it
+// leaves out trashed tasks and filters on the context's workspace itself.
+func (s *GormTaskStore) searchFullText(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	// The hits are delimited with control characters, replaced with
+	// <mark> tags once the text around them is escaped.
+	marks := "StartSel=" + headlineStart + ", StopSel=" + headlineStop
+	where, args := taskSearchVector+" @@ query AND tasks.deleted_at IS NULL", []any{
+		marks + ", HighlightAll=true",
+		marks + ", MaxFragments=2, MaxWords=30, MinWords=10",
+		query,
+	}
+	if workspaceID, ok := workspaceFrom(ctx); ok {
+		where += " AND tasks.workspace_id = ?"
+		args = append(args, workspaceID)
+	}
+	args = append(args, limit)
+
+	results := []SearchResult{}
+	err := dbFor(ctx, s.db).Raw(`
+		SELECT tasks.*,
+			ts_rank(`+taskSearchVector+`, query) AS rank,
+			ts_headline('english', title, query, ?) AS highlight,
+			ts_headline('english', description, query, ?) AS snippet
+		FROM tasks, websearch_to_tsquery('english', ?) AS query
+		WHERE `+where+`
+		ORDER BY rank DESC, id DESC
+		LIMIT ?`, args...).Scan(&results).Error
+	for i := range results {
+		results[i].Highlight = markHeadline(results[i].Highlight)
+		results[i].Snippet = markHeadline(results[i].Snippet)
+	}
+	return results, err
+}
@@ -0,0 +1,348 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryTaskStore is a TaskStore that keeps tasks in process memory.
+// It is intended for tests and local experiments; data is lost on restart.
+// Like the GORM stores, it limits every operation to the workspace of the
+// context.
+type MemoryTaskStore struct {
+	mu     sync.RWMutex
+	nextID uint
+	tasks  map[uint]Task
+}
+
+// NewMemoryTaskStore returns an empty in-memory TaskStore.
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{
+		nextID: 1,
+		tasks:  make(map[uint]Task),
+	}
+}
+
+// live reports whether task is outside the trash and in the workspace of ctx.
+func live(ctx context.Context, task *Task) bool {
+	return !task.DeletedAt.Valid && inWorkspace(ctx, task.WorkspaceID)
+}
+
+// matches reports whether task satisfies filter.
+func (f TaskFilter) matches(task *Task) bool {
+	if f.Completed != nil && task.Completed != *f.Completed {
+		return false
+	}
+	if f.CreatedAfter != nil && !task.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !task.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.BoardID != nil && (task.BoardID == nil || *task.BoardID != *f.BoardID) {
+		return false
+	}
+	if f.ColumnID != nil && (task.ColumnID == nil || *task.ColumnID != *f.ColumnID) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, task.Priority) {
+		return false
+	}
+	if f.DueAfter != nil && (task.DueAt == nil || !task.DueAt.After(*f.DueAfter)) {
+		return false
+	}
+	if f.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.Overdue != nil && task.IsOverdue(time.Now()) != *f.Overdue {
+		return false
+	}
+	if f.Recurring != nil && (task.Recurrence != "") != *f.Recurring {
+		return false
+	}
+	if f.AssigneeID != nil && (task.AssigneeID == nil || *task.AssigneeID != *f.AssigneeID) {
+		return false
+	}
+	if f.Unassigned && task.AssigneeID != nil {
+		return false
+	}
+	if len(f.Labels) > 0 {
+		matched := 0
+		for _, name := range f.Labels {
+			if task.hasLabel(name) {
+				matched++
+			}
+		}
+		if matched == 0 || f.AllLabels && matched < len(f.Labels) {
+			return false
+		}
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(f.Query)) {
+		return false
+	}
+	return true
+}
+
+// compareTasks orders a and b by field, breaking ties by ID. It returns
+// a negative number when a sorts first in ascending order.
+func compareTasks(a, b *Task, field string) int {
+	var c int
+	switch field {
+	case SortUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortTitle:
+		c = strings.Compare(a.Title, b.Title)
+	case SortPosition:
+		c = cmp.Compare(a.Position, b.Position)
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	return c
+}
+
+// List returns the tasks matching filter in the order and page selected by opts.
+func (s *MemoryTaskStore) List(ctx context.Context, filter TaskFilter, opts ListOptions) ([]Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	order := opts.Sort.orDefault()
+	if !validSortField(order.Field) {
+		return nil, fmt.Errorf("%w %q", errUnsupportedSort, order.Field)
+	}
+
+	direction := 1
+	if order.Desc {
+		direction = -1
+	}
+
+	var after *Task
+	if opts.After != nil {
+		after = &Task{
+			ID:        opts.After.ID,
+			CreatedAt: opts.After.CreatedAt,
+			UpdatedAt: opts.After.UpdatedAt,
+			Title:     opts.After.Title,
+			Position:  opts.After.Position,
+		}
+	}
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if !live(ctx, &task) || !filter.matches(&task) {
+			continue
+		}
+		if after != nil && direction*compareTasks(&task, after, order.Field) <= 0 {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	slices.SortFunc(tasks, func(a, b Task) int {
+		return direction * compareTasks(&a, &b, order.Field)
+	})
+
+	if opts.Limit > 0 && len(tasks) > opts.Limit {
+		tasks = tasks[:opts.Limit]
+	}
+
+	return tasks, nil
+}
+
+// Get returns the task with the given ID.
+func (s *MemoryTaskStore) Get(ctx context.Context, id uint) (*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !live(ctx, &task) {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+// Create stores a new task and assigns it the next free ID.
+func (s *MemoryTaskStore) Create(ctx context.Context, task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if workspaceID, ok := workspaceFrom(ctx); ok {
+		task.WorkspaceID = workspaceID
+	}
+
+	now := time.Now()
+	if task.Priority == "" {
+		task.Priority = PriorityMedium
+	}
+	task.ID = s.nextID
+	task.Version = 1
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	s.nextID++
+
+	s.tasks[task.ID] = *task
+	return nil
+}
+
+// Update replaces an existing task if its version is unchanged.
+func (s *MemoryTaskStore) Update(ctx context.Context, task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.tasks[task.ID]
+	if !ok || !live(ctx, &current) {
+		return ErrTaskNotFound
+	}
+	if task.Version != current.Version {
+		return ErrVersionConflict
+	}
+	task.WorkspaceID = current.WorkspaceID
+
+	task.Version++
+	task.UpdatedAt = time.Now()
+	s.tasks[task.ID] = *task
+	return nil
+}
+
+// Delete moves the task with the given ID to the trash.
+func (s *MemoryTaskStore) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, ok := s.tasks[id]; ok && live(ctx, &task) {
+		task.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		s.tasks[id] = task
+	}
+	return nil
+}
+
+// DeleteAll moves every task to the trash.
+func (s *MemoryTaskStore) DeleteAll(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, task := range s.tasks {
+		if live(ctx, &task) {
+			task.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			s.tasks[id] = task
+		}
+	}
+	return nil
+}
+
+// inTrash reports whether task is in the trash of the workspace of ctx.
+func inTrash(ctx context.Context, task *Task) bool {
+	return task.DeletedAt.Valid && inWorkspace(ctx, task.WorkspaceID)
+}
+
+// ListDeleted returns the tasks in the trash, most recently deleted first.
+func (s *MemoryTaskStore) ListDeleted(ctx context.Context) ([]Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := []Task{}
+	for _, task := range s.tasks {
+		if inTrash(ctx, &task) {
+			tasks = append(tasks, task)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b Task) int {
+		if c := b.DeletedAt.Time.Compare(a.DeletedAt.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return tasks, nil
+}
+
+// GetDeleted returns the task with the given ID from the trash.
+func (s *MemoryTaskStore) GetDeleted(ctx context.Context, id uint) (*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !inTrash(ctx, &task) {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+// Restore takes a task out of the trash. Tasks in memory are never on a
+// board, so it stays where it was.
+func (s *MemoryTaskStore) Restore(ctx context.Context, id uint) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !inTrash(ctx, &task) {
+		return nil, ErrTaskNotFound
+	}
+	task.DeletedAt = gorm.DeletedAt{}
+	task.Version++
+	task.UpdatedAt = time.Now()
+	s.tasks[id] = task
+	return &task, nil
+}
+
+// Purge permanently removes the tasks trashed before cutoff.
+func (s *MemoryTaskStore) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, task := range s.tasks {
+		if inTrash(ctx, &task) && task.DeletedAt.Time.Before(cutoff) {
+			delete(s.tasks, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Count returns the number of tasks matching filter.
+func (s *MemoryTaskStore) Count(ctx context.Context, filter TaskFilter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, task := range s.tasks {
+		if live(ctx, &task) && filter.matches(&task) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Search returns the tasks whose title and description together contain
+// every word of query.
+func (s *MemoryTaskStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matcher := newTermMatcher(terms)
+	results := []SearchResult{}
+	for _, task := range s.tasks {
+		if !live(ctx, &task) {
+			continue
+		}
+		if result, ok := matcher.match(&task); ok {
+			results = append(results, result)
+		}
+	}
+	return rankResults(results, limit), nil
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashConfig controls how long deleted tasks stay in the trash.
+type TrashConfig struct {
+	// Retention is how long a task stays in the trash before the purger
+	// removes it for good.
+	Retention time.Duration
+	// PurgeInterval is how often the purger runs.
+	PurgeInterval time.Duration
+}
+
+// LoadTrashConfig reads the trash settings from the environment.
+func LoadTrashConfig() (TrashConfig, error) {
+	var cfg TrashConfig
+	var err error
+	if cfg.Retention, err = time.ParseDuration(getEnv("TRASH_RETENTION", "720h")); err != nil {
+		return cfg, fmt.Errorf("invalid TRASH_RETENTION: %w", err)
+	}
+	if cfg.PurgeInterval, err = time.ParseDuration(getEnv("TRASH_PURGE_INTERVAL", "1h")); err != nil {
+		return cfg, fmt.Errorf("invalid TRASH_PURGE_INTERVAL: %w", err)
+	}
+	if cfg.PurgeInterval <= 0 {
+		return cfg, errors.New("invalid TRASH_PURGE_INTERVAL: must be positive")
+	}
+	return cfg, nil
+}
+
+// PurgeTrash permanently removes tasks that have been in the trash for
+// longer than the retention period, right away and then every purge
+// interval, until ctx is done. It purges every workspace.
+func PurgeTrash(ctx context.Context, tasks TaskStore, cfg TrashConfig) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		var purged int64
+		err := TrackDBOperation(ctx, "purge_trash", func(ctx context.Context) error {
+			var err error
+			purged, err = tasks.Purge(ctx, time.Now().Add(-cfg.Retention))
+			return err
+		})
+		switch {
+		case err != nil:
+			log.Printf("Failed to purge trash: %v", err)
+		case purged > 0:
+			log.Printf("Purged %d tasks from the trash", purged)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// listTrash returns the deleted tasks that can still be restored, most
+// recently deleted first.
+func (s *Server) listTrash(c *gin.Context) {
+	var tasks []Task
+	err := TrackDBOperation(c.Request.Context(), "list_deleted_tasks", func(ctx context.Context) error {
+		var err error
+		tasks, err = s.tasks.ListDeleted(ctx)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// restoreTask takes a task out of the trash. Restoring a task of a board
+// takes the same role as deleting it.
+func (s *Server) restoreTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "find_deleted_task", func(ctx context.Context) error {
+		var err error
+		task, err = s.tasks.GetDeleted(ctx, id)
+		return err
+	})
+	if err == nil && !s.authorizeTask(c, task.BoardID, RoleEditor) {
+		return
+	}
+
+	if err == nil {
+		err = TrackDBOperation(c.Request.Context(), "restore_task", func(ctx context.Context) error {
+			return s.inTx(ctx, func(ctx context.Context) error {
+				restored, err := s.tasks.Restore(ctx, id)
+				if err != nil {
+					return err
+				}
+				if err := s.recordTaskEvent(ctx, TaskRestored, task, restored); err != nil {
+					return err
+				}
+				task = restored
+				return nil
+			})
+		})
+	}
+
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found in trash")
+	case err != nil:
+		writeInternalError(c, err, "failed to restore task")
+	default:
+		// Update metrics after the task is back
+		s.refreshTaskMetrics(c)
+		writeTask(c, http.StatusOK, task)
+	}
+}
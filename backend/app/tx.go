@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Transactor runs a function inside a database transaction. Stores used
+// with the context passed to the function take part in the transaction.
+type Transactor interface {
+	// InTx commits the transaction when fn returns nil and rolls it back
+	// otherwise. Called again with that context, it starts a nested
+	// transaction that can be rolled back on its own.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txKey is the context key holding the transaction started by InTx.
+type txKey struct{}
+
+// dbFor returns db for a store operation on ctx, joining the transaction
+// ctx carries, if any.
+func dbFor(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// GormTransactor is a Transactor for the GORM stores sharing its database.
+type GormTransactor struct {
+	db *gorm.DB
+}
+
+// NewGormTransactor returns a Transactor running transactions on db.
+func NewGormTransactor(db *gorm.DB) *GormTransactor {
+	return &GormTransactor{db: db}
+}
+
+// InTx runs fn in a transaction, or in a savepoint when ctx is already
+// inside one.
+func (t *GormTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbFor(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// inTx runs fn in a transaction of the server's stores, or directly when
+// they do not support transactions. The events fn publishes are delivered,
+// and the outbox relay notified, once it succeeded and the outermost
+// transaction committed.
+func (s *Server) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	pending := &pendingEvents{}
+	run := func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, pendingKey{}, pending))
+	}
+
+	var err error
+	if s.tx == nil {
+		err = run(ctx)
+	} else {
+		err = s.tx.InTx(ctx, run)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, pending.events...)
+	if pending.outbox {
+		s.wakeRelay(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches the requested ID.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists is returned when creating or updating a user with an
+	// email that is already taken in its workspace.
+	ErrUserExists = errors.New("user already exists")
+)
+
+// UserStore persists users and the assignees and watchers of tasks. It
+// shares the tasks table with the TaskStore of the same backend.
+type UserStore interface {
+	// ListUsers returns every user ordered by name.
+	ListUsers(ctx context.Context) ([]User, error)
+	GetUser(ctx context.Context, id uint) (*User, error)
+	// ListUsersByEmail returns the users with the given email, one per
+	// workspace they belong to, oldest first.
+	ListUsersByEmail(ctx context.Context, email string) ([]User, error)
+	CreateUser(ctx context.Context, user *User) error
+	UpdateUser(ctx context.Context, user *User) error
+	// DeleteUser removes a user, unassigning their tasks.
+	DeleteUser(ctx context.Context, id uint) error
+
+	// AssignTask sets the assignee of a task, or clears it when userID is
+	// nil, and returns the updated task.
+	AssignTask(ctx context.Context, taskID uint, userID *uint) (*Task, error)
+	// AddWatcher subscribes a user to a task and returns the updated task.
+	AddWatcher(ctx context.Context, taskID, userID uint) (*Task, error)
+	// RemoveWatcher unsubscribes a user from a task and returns the
+	// updated task.
+	RemoveWatcher(ctx context.Context, taskID, userID uint) (*Task, error)
+}
+
+// GormUserStore is a UserStore backed by a GORM database connection.
+type GormUserStore struct {
+	db *gorm.DB
+}
+
+// NewGormUserStore returns a UserStore that persists users through db.
+func NewGormUserStore(db *gorm.DB) *GormUserStore {
+	return &GormUserStore{db: db}
+}
+
+// orderedUsers sorts preloaded or queried users for display.
+func orderedUsers(db *gorm.DB) *gorm.DB {
+	return db.Order("users.name asc, users.id asc")
+}
+
+// userError maps a unique constraint violation to ErrUserExists. It
+// relies on OpenDB enabling error translation.
+func userError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrUserExists
+	}
+	return err
+}
+
+// findUser loads a user through db, which may be a transaction.
+func findUser(db *gorm.DB, id uint) (*User, error) {
+	var user User
+	err := db.First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers returns every user ordered by name.
+func (s *GormUserStore) ListUsers(ctx context.Context) ([]User, error) {
+	users := []User{}
+	err := orderedUsers(dbFor(ctx, s.db)).Find(&users).Error
+	return users, err
+}
+
+// GetUser returns the user with the given ID.
+func (s *GormUserStore) GetUser(ctx context.Context, id uint) (*User, error) {
+	return findUser(dbFor(ctx, s.db), id)
+}
+
+// ListUsersByEmail returns the users with the given email, oldest first.
+func (s *GormUserStore) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	users := []User{}
+	err := dbFor(ctx, s.db).Where("email = ?", email).Order("id asc").Find(&users).Error
+	return users, err
+}
+
+// CreateUser inserts a new user.
+func (s *GormUserStore) CreateUser(ctx context.Context, user *User) error {
+	return userError(dbFor(ctx, s.db).Create(user).Error)
+}
+
+// UpdateUser saves all fields of an existing user.
+func (s *GormUserStore) UpdateUser(ctx context.Context, user *User) error {
+	return userError(dbFor(ctx, s.db).Save(user).Error)
+}
+
+// DeleteUser removes a user. Foreign keys clear the assignee of their
+// tasks and drop their watches.
+func (s *GormUserStore) DeleteUser(ctx context.Context, id uint) error {
+	res := dbFor(ctx, s.db).Delete(&User{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// AssignTask sets or clears the assignee of a task.
+func (s *GormUserStore) AssignTask(ctx context.Context, taskID uint, userID *uint) (*Task, error) {
+	var task *Task
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := taskExists(tx, taskID); err != nil {
+			return err
+		}
+		if userID != nil {
+			if _, err := findUser(tx, *userID); err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&Task{ID: taskID}).Updates(map[string]any{"assignee_id": userID, "version": nextVersion}).Error
+		if err != nil {
+			return err
+		}
+
+		task, err = findTask(tx, taskID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// AddWatcher subscribes a user to a task. Adding an existing watcher is
+// not an error.
+func (s *GormUserStore) AddWatcher(ctx context.Context, taskID, userID uint) (*Task, error) {
+	return s.updateWatchers(ctx, taskID, userID, func(watchers *gorm.Association, user *User) error {
+		return watchers.Append(user)
+	})
+}
+
+// RemoveWatcher unsubscribes a user from a task. Removing a user who is
+// not watching is not an error.
+func (s *GormUserStore) RemoveWatcher(ctx context.Context, taskID, userID uint) (*Task, error) {
+	return s.updateWatchers(ctx, taskID, userID, func(watchers *gorm.Association, user *User) error {
+		return watchers.Delete(user)
+	})
+}
+
+// updateWatchers applies change to the watchers of a task once both the
+// task and the user are known to exist, bumping the task's version when
+// its watchers changed.
+func (s *GormUserStore) updateWatchers(ctx context.Context, taskID, userID uint, change func(*gorm.Association, *User) error) (*Task, error) {
+	var task *Task
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if task, err = findTask(tx, taskID); err != nil {
+			return err
+		}
+		user, err := findUser(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Only write the join rows; the user itself is unchanged.
+		watchers := len(task.Watchers)
+		if err := change(tx.Omit("Watchers.*").Model(task).Association("Watchers"), user); err != nil {
+			return err
+		}
+
+		if task, err = findTask(tx, taskID); err != nil || len(task.Watchers) == watchers {
+			return err
+		}
+		if err := tx.Model(&Task{ID: taskID}).Update("version", nextVersion).Error; err != nil {
+			return err
+		}
+		task, err = findTask(tx, taskID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
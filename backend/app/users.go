@@ -0,0 +1,373 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeUserError maps UserStore errors to HTTP responses.
+func writeUserError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		writeError(c, http.StatusNotFound, "user not found")
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, http.StatusNotFound, "task not found")
+	case errors.Is(err, ErrUserExists):
+		writeError(c, http.StatusConflict, "a user with this email already exists in the workspace")
+	default:
+		writeInternalError(c, err, fallback)
+	}
+}
+
+// listUsers returns all users ordered by name.
+func (s *Server) listUsers(c *gin.Context) {
+	var users []User
+	err := TrackDBOperation(c.Request.Context(), "query_all_users", func(ctx context.Context) error {
+		var err error
+		users, err = s.users.ListUsers(ctx)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch users")
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUserInput represents the expected payload for creating a user.
+// Users created without a password cannot log in until one is set.
+type CreateUserInput struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"omitempty,min=8,max=72"`
+	// Role is the user's role in the workspace, editor by default.
+	Role Role `json:"role" binding:"omitempty,oneof=viewer editor owner"`
+}
+
+// createUser creates a user. When authentication is enabled, users may
+// only grant a workspace role they hold themselves.
+func (s *Server) createUser(c *gin.Context) {
+	var input CreateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	user := User{Name: input.Name, Email: normalizeEmail(input.Email), Role: input.Role}
+	if user.Role == "" {
+		user.Role = RoleEditor
+	}
+	if !s.authorizeWorkspace(c, user.Role) {
+		return
+	}
+	if input.Password != "" {
+		var err error
+		if user.PasswordHash, err = hashPassword(input.Password); err != nil {
+			writeInternalError(c, err, "failed to create user")
+			return
+		}
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "create_user", func(ctx context.Context) error {
+		return s.users.CreateUser(ctx, &user)
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to create user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// getUser returns a single user.
+func (s *Server) getUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var user *User
+	err := TrackDBOperation(c.Request.Context(), "find_user", func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetUser(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to fetch user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// getCurrentUser returns the authenticated user.
+func (s *Server) getCurrentUser(c *gin.Context) {
+	id, ok := currentUserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+
+	var user *User
+	err := TrackDBOperation(c.Request.Context(), "find_user", func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetUser(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to fetch user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUserInput represents the fields that can be updated in a user.
+type UpdateUserInput struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Email    *string `json:"email" binding:"omitempty,email,max=255"`
+	Password *string `json:"password" binding:"omitempty,min=8,max=72"`
+}
+
+// updateUser changes the name, email or password of a user. When
+// authentication is enabled users may only update themselves.
+func (s *Server) updateUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if userID, ok := currentUserID(c); ok && userID != id {
+		writeError(c, http.StatusForbidden, "cannot update another user")
+		return
+	}
+
+	var input UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var passwordHash string
+	if input.Password != nil {
+		var err error
+		if passwordHash, err = hashPassword(*input.Password); err != nil {
+			writeInternalError(c, err, "failed to update user")
+			return
+		}
+	}
+
+	var user *User
+	err := TrackDBOperation(c.Request.Context(), "update_user", func(ctx context.Context) error {
+		var err error
+		if user, err = s.users.GetUser(ctx, id); err != nil {
+			return err
+		}
+		if input.Name != nil {
+			user.Name = *input.Name
+		}
+		if input.Email != nil {
+			user.Email = normalizeEmail(*input.Email)
+		}
+		if passwordHash != "" {
+			user.PasswordHash = passwordHash
+		}
+		return s.users.UpdateUser(ctx, user)
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to update user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// deleteUser deletes a user and unassigns their tasks. When
+// authentication is enabled users may only delete themselves.
+func (s *Server) deleteUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if userID, ok := currentUserID(c); ok && userID != id {
+		writeError(c, http.StatusForbidden, "cannot delete another user")
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_user", func(ctx context.Context) error {
+		return s.users.DeleteUser(ctx, id)
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to delete user")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TaskUserInput identifies the user to assign to, or watch, a task.
+type TaskUserInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// setAssignee assigns a task to a user, or unassigns it when userID is
+// nil, and records the change.
+func (s *Server) setAssignee(ctx context.Context, taskID uint, userID *uint) (*Task, error) {
+	before, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.users.AssignTask(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordTaskEvent(ctx, TaskUpdated, before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// assignTask sets the assignee of a task.
+func (s *Server) assignTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input TaskUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "assign_task", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			task, err = s.setAssignee(ctx, id, &input.UserID)
+			return err
+		})
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to assign task")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// unassignTask clears the assignee of a task.
+func (s *Server) unassignTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "unassign_task", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			task, err = s.setAssignee(ctx, id, nil)
+			return err
+		})
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to unassign task")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// setWatching subscribes a user to a task, or unsubscribes them, and
+// records the change.
+func (s *Server) setWatching(ctx context.Context, taskID, userID uint, watching bool) (*Task, error) {
+	before, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var task *Task
+	if watching {
+		task, err = s.users.AddWatcher(ctx, taskID, userID)
+	} else {
+		task, err = s.users.RemoveWatcher(ctx, taskID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordTaskEvent(ctx, TaskUpdated, before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// addWatcher subscribes a user to a task.
+func (s *Server) addWatcher(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input TaskUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "add_watcher", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			task, err = s.setWatching(ctx, id, input.UserID, true)
+			return err
+		})
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to add watcher")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// removeWatcher unsubscribes a user from a task.
+func (s *Server) removeWatcher(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var task *Task
+	err := TrackDBOperation(c.Request.Context(), "remove_watcher", func(ctx context.Context) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			var err error
+			task, err = s.setWatching(ctx, id, userID, false)
+			return err
+		})
+	})
+
+	if err != nil {
+		writeUserError(c, err, "failed to remove watcher")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWebhookNotFound is returned when no webhook matches the requested ID.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// DeliveryListOptions selects a page of the delivery log of a webhook.
+type DeliveryListOptions struct {
+	WebhookID uint
+	// Limit caps the number of returned deliveries; zero means no limit.
+	Limit int
+	// Before resumes the listing with the deliveries older than the one
+	// with this ID.
+	Before uint
+}
+
+// WebhookStore persists webhooks and their queue of deliveries.
+// Deliveries are queued in the transaction of the change they announce
+// when the context carries one; see Transactor.
+type WebhookStore interface {
+	// ListWebhooks returns the webhooks of the workspace, newest first.
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (*Webhook, error)
+	CreateWebhook(ctx context.Context, webhook *Webhook) error
+	// DeleteWebhook removes a webhook and its deliveries.
+	DeleteWebhook(ctx context.Context, id uint) error
+
+	// EnqueueDeliveries queues payload for every webhook of a workspace
+	// subscribed to event, due right away.
+	EnqueueDeliveries(ctx context.Context, workspaceID uint, event string, payload json.RawMessage) error
+	// ListDeliveries returns the deliveries selected by opts, newest first.
+	ListDeliveries(ctx context.Context, opts DeliveryListOptions) ([]WebhookDelivery, error)
+	// ClaimDeliveries returns up to limit pending deliveries due at now,
+	// oldest first and with their webhook, and postpones them until
+	// now+lease so that concurrent dispatchers skip them meanwhile.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	// SaveDelivery records the outcome of an attempt.
+	SaveDelivery(ctx context.Context, delivery *WebhookDelivery) error
+}
+
+// GormWebhookStore is a WebhookStore backed by a GORM database connection.
+type GormWebhookStore struct {
+	db *gorm.DB
+}
+
+// NewGormWebhookStore returns a WebhookStore that persists webhooks through db.
+func NewGormWebhookStore(db *gorm.DB) *GormWebhookStore {
+	return &GormWebhookStore{db: db}
+}
+
+// ListWebhooks returns the webhooks of the workspace, newest first.
+func (s *GormWebhookStore) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	webhooks := []Webhook{}
+	err := dbFor(ctx, s.db).Order("id desc").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetWebhook returns a webhook by ID.
+func (s *GormWebhookStore) GetWebhook(ctx context.Context, id uint) (*Webhook, error) {
+	var webhook Webhook
+	err := dbFor(ctx, s.db).First(&webhook, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// CreateWebhook inserts a new webhook.
+func (s *GormWebhookStore) CreateWebhook(ctx context.Context, webhook *Webhook) error {
+	return dbFor(ctx, s.db).Create(webhook).Error
+}
+
+// DeleteWebhook removes a webhook and its deliveries in one transaction.
+func (s *GormWebhookStore) DeleteWebhook(ctx context.Context, id uint) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&Webhook{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&WebhookDelivery{}).Error
+	})
+}
+
+// EnqueueDeliveries inserts a pending delivery for every matching webhook.
+func (s *GormWebhookStore) EnqueueDeliveries(ctx context.Context, workspaceID uint, event string, payload json.RawMessage) error {
+	db := dbFor(ctx, s.db)
+
+	var webhooks []Webhook
+	if err := db.Where("workspace_id = ?", workspaceID).Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []WebhookDelivery
+	for _, webhook := range webhooks {
+		if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event) {
+			continue
+		}
+		deliveries = append(deliveries, WebhookDelivery{
+			WorkspaceID:   workspaceID,
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.Omit("Webhook").Create(&deliveries).Error
+}
+
+// ListDeliveries returns a page of deliveries ordered by descending ID,
+// which is the order they were queued in.
+func (s *GormWebhookStore) ListDeliveries(ctx context.Context, opts DeliveryListOptions) ([]WebhookDelivery, error) {
+	query := dbFor(ctx, s.db).Where("webhook_id = ?", opts.WebhookID).Order("id desc")
+	if opts.Before != 0 {
+		query = query.Where("id < ?", opts.Before)
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	deliveries := []WebhookDelivery{}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDeliveries selects and postpones due deliveries in one
+// transaction. On PostgreSQL the rows are locked, skipping those another
+// dispatcher is claiming; SQLite already serializes writers.
+func (s *GormWebhookStore) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+	err := dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		query := tx.Preload("Webhook").
+			Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit)
+		if tx.Dialector.Name() == DriverPostgres {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&deliveries).Error; err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&WebhookDelivery{}).Where("id IN ?", ids).UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+// SaveDelivery writes the attempt fields of a delivery.
+func (s *GormWebhookStore) SaveDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	return dbFor(ctx, s.db).Model(delivery).
+		Select("Status", "Attempts", "NextAttemptAt", "ResponseStatus", "LastError", "DeliveredAt").
+		Updates(delivery).Error
+}
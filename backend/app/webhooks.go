@@ -0,0 +1,483 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// webhookBatchSize is the number of deliveries a dispatcher claims at
+	// once.
+	webhookBatchSize = 50
+	// webhookMaxBackoff caps the delay between two attempts of a delivery.
+	webhookMaxBackoff = 6 * time.Hour
+	// webhookSignatureHeader carries the HMAC-SHA256 of the delivered body,
+	// keyed with the webhook's secret, as sha256=<hex>.
+	webhookSignatureHeader = "X-TaskBoard-Signature"
+)
+
+// WebhookConfig controls the delivery of webhooks.
+type WebhookConfig struct {
+	// PollInterval is how often the dispatcher looks for due deliveries.
+	PollInterval time.Duration
+	// Timeout bounds each attempt; an attempt still running then fails.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts after which a delivery fails
+	// for good.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubling after
+	// every further attempt.
+	RetryBackoff time.Duration
+	// AllowPrivateTargets lets webhooks reach loopback, private and
+	// link-local addresses, which are refused by default so that
+	// webhooks cannot probe the network the server runs in. Meant for
+	// local development.
+	AllowPrivateTargets bool
+}
+
+// LoadWebhookConfig reads the webhook settings from the environment.
+func LoadWebhookConfig() (WebhookConfig, error) {
+	var cfg WebhookConfig
+	var err error
+	if cfg.PollInterval, err = time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "5s")); err != nil || cfg.PollInterval <= 0 {
+		return cfg, errors.New("invalid WEBHOOK_POLL_INTERVAL: expected a positive duration")
+	}
+	if cfg.Timeout, err = time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s")); err != nil || cfg.Timeout <= 0 {
+		return cfg, errors.New("invalid WEBHOOK_TIMEOUT: expected a positive duration")
+	}
+	if cfg.MaxAttempts, err = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8")); err != nil || cfg.MaxAttempts < 1 {
+		return cfg, errors.New("invalid WEBHOOK_MAX_ATTEMPTS: expected a positive number")
+	}
+	if cfg.RetryBackoff, err = time.ParseDuration(getEnv("WEBHOOK_RETRY_BACKOFF", "30s")); err != nil {
+		return cfg, fmt.Errorf("invalid WEBHOOK_RETRY_BACKOFF: %w", err)
+	}
+	if cfg.AllowPrivateTargets, err = strconv.ParseBool(getEnv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "false")); err != nil {
+		return cfg, fmt.Errorf("invalid WEBHOOK_ALLOW_PRIVATE_TARGETS: %w", err)
+	}
+	return cfg, nil
+}
+
+// errPrivateTarget is returned for webhooks targeting an address that is
+// not publicly routable.
+var errPrivateTarget = errors.New("webhook targets a private address")
+
+// cgnatPrefix is the shared address space of carrier-grade NAT (RFC 6598).
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPrivateAddr reports whether addr is not publicly routable: loopback,
+// private, link-local, shared, unspecified or multicast.
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified() || cgnatPrefix.Contains(addr)
+}
+
+// checkWebhookTarget returns errPrivateTarget when the host of target is,
+// or resolves to, a private address. Hosts that do not resolve are left to
+// the dispatcher, which checks every address it connects to.
+func checkWebhookTarget(ctx context.Context, target *url.URL) error {
+	host := target.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivateAddr(addr) {
+			return errPrivateTarget
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if isPrivateAddr(addr) {
+			return errPrivateTarget
+		}
+	}
+	return nil
+}
+
+// newWebhookClient returns the HTTP client of a dispatcher. Unless cfg
+// allows private targets, it refuses to connect to private addresses,
+// including through redirects and hosts resolving to them.
+func newWebhookClient(cfg WebhookConfig) *http.Client {
+	if cfg.AllowPrivateTargets {
+		return &http.Client{Timeout: cfg.Timeout}
+	}
+
+	dialer := &net.Dialer{
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if addr, err := netip.ParseAddr(host); err != nil || isPrivateAddr(addr) {
+				return errPrivateTarget
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return checkWebhookTarget(req.Context(), req.URL)
+		},
+	}
+}
+
+// WebhookPayload is the JSON body delivered to webhooks.
+type WebhookPayload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Task is the task after the change, or before it for deletions.
+	Task *Task `json:"task"`
+}
+
+// signWebhook returns the signature header value of body.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSink queues events for the webhooks of their workspace, to be
+// delivered by a WebhookDispatcher.
+type WebhookSink struct {
+	Webhooks WebhookStore
+}
+
+// Handle implements EventSink.
+func (s WebhookSink) Handle(ctx context.Context, event HubEvent) error {
+	payload, err := json.Marshal(WebhookPayload{Event: event.Type, OccurredAt: time.Now().UTC(), Task: event.Task})
+	if err != nil {
+		return err
+	}
+	return TrackDBOperation(ctx, "enqueue_webhook_deliveries", func(ctx context.Context) error {
+		return s.Webhooks.EnqueueDeliveries(ctx, event.WorkspaceID, event.Type, payload)
+	})
+}
+
+// WebhookDispatcher delivers the queued deliveries of every workspace.
+// Several dispatchers, in as many replicas, may share a queue: each claims
+// the deliveries it attempts.
+type WebhookDispatcher struct {
+	store  WebhookStore
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookDispatcher returns a dispatcher of the deliveries in store.
+func NewWebhookDispatcher(store WebhookStore, cfg WebhookConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{store: store, cfg: cfg, client: newWebhookClient(cfg)}
+}
+
+// Run delivers due deliveries right away and then every poll interval,
+// until ctx is done.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil {
+			log.Printf("Failed to deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DeliverDue attempts every delivery that is due, returning how many it
+// attempted. The deliveries of a batch are attempted concurrently.
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	attempted := 0
+	for ctx.Err() == nil {
+		var deliveries []WebhookDelivery
+		err := TrackDBOperation(ctx, "claim_webhook_deliveries", func(ctx context.Context) error {
+			var err error
+			// A claim outlasts the attempts, so nobody else retries them
+			// meanwhile: they run at once, each bounded by the timeout.
+			deliveries, err = d.store.ClaimDeliveries(ctx, time.Now(), 2*d.cfg.Timeout, webhookBatchSize)
+			return err
+		})
+		if err != nil {
+			return attempted, err
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(ctx, &deliveries[i])
+			}()
+		}
+		wg.Wait()
+
+		for i := range deliveries {
+			err := TrackDBOperation(ctx, "save_webhook_delivery", func(ctx context.Context) error {
+				return d.store.SaveDelivery(ctx, &deliveries[i])
+			})
+			if err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+		if len(deliveries) < webhookBatchSize {
+			break
+		}
+	}
+	return attempted, nil
+}
+
+// attempt posts a delivery to its webhook and records the outcome,
+// scheduling the next attempt after a failure.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *WebhookDelivery) {
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+	delivery.LastError = ""
+
+	err := d.post(ctx, delivery)
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = DeliveryDelivered
+		delivery.DeliveredAt = &now
+		return
+	case delivery.Webhook == nil || delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = DeliveryFailed
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > 500 {
+		delivery.LastError = delivery.LastError[:500]
+	}
+}
+
+// post sends a delivery, failing unless the webhook answers with 2xx.
+func (d *WebhookDispatcher) post(ctx context.Context, delivery *WebhookDelivery) error {
+	if delivery.Webhook == nil {
+		return errors.New("webhook deleted")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TaskBoard-Webhooks")
+	req.Header.Set("X-TaskBoard-Event", delivery.Event)
+	req.Header.Set("X-TaskBoard-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhookSignatureHeader, signWebhook(delivery.Webhook.Secret, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// Drain a little of the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	delivery.ResponseStatus = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the delay before the attempt following the given one.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff)
+}
+
+// writeWebhookError maps WebhookStore errors to HTTP responses.
+func writeWebhookError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, ErrWebhookNotFound) {
+		writeError(c, http.StatusNotFound, "webhook not found")
+		return
+	}
+	writeInternalError(c, err, fallback)
+}
+
+// listWebhooks returns the webhooks of the workspace.
+func (s *Server) listWebhooks(c *gin.Context) {
+	var webhooks []Webhook
+	err := TrackDBOperation(c.Request.Context(), "query_webhooks", func(ctx context.Context) error {
+		var err error
+		webhooks, err = s.webhooks.ListWebhooks(ctx)
+		return err
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to fetch webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhookInput represents the expected payload for creating a
+// webhook. A webhook without events receives every event.
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required,http_url,max=2048"`
+	Events []string `json:"events" binding:"omitempty,dive,oneof=task.created task.updated task.deleted task.restored"`
+	Secret string   `json:"secret" binding:"required,min=16,max=255"`
+}
+
+// AllowPrivateWebhookTargets lets webhooks be created for loopback,
+// private and link-local addresses; see WebhookConfig.AllowPrivateTargets.
+func (s *Server) AllowPrivateWebhookTargets() {
+	s.privateWebhooks = true
+}
+
+// createWebhook subscribes a URL to the task events of the workspace.
+// URLs targeting private addresses are refused unless allowed.
+func (s *Server) createWebhook(c *gin.Context) {
+	var input CreateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+	if !s.privateWebhooks {
+		target, err := url.Parse(input.URL)
+		if err == nil {
+			err = checkWebhookTarget(c.Request.Context(), target)
+		}
+		if err != nil {
+			problem := newProblem(http.StatusBadRequest, "invalid input")
+			problem.Errors = []FieldError{{Field: "url", Message: "must not target a private address"}}
+			writeProblem(c, problem)
+			return
+		}
+	}
+
+	webhook := Webhook{URL: input.URL, Events: input.Events, Secret: input.Secret}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "create_webhook", func(ctx context.Context) error {
+		return s.webhooks.CreateWebhook(ctx, &webhook)
+	})
+
+	if err != nil {
+		writeInternalError(c, err, "failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// getWebhook returns a single webhook.
+func (s *Server) getWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var webhook *Webhook
+	err := TrackDBOperation(c.Request.Context(), "find_webhook", func(ctx context.Context) error {
+		var err error
+		webhook, err = s.webhooks.GetWebhook(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		writeWebhookError(c, err, "failed to fetch webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// deleteWebhook removes a webhook, dropping its pending deliveries.
+func (s *Server) deleteWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := TrackDBOperation(c.Request.Context(), "delete_webhook", func(ctx context.Context) error {
+		return s.webhooks.DeleteWebhook(ctx, id)
+	})
+
+	if err != nil {
+		writeWebhookError(c, err, "failed to delete webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listDeliveries returns the delivery log of a webhook, newest first and
+// paginated like GET /activity.
+func (s *Server) listDeliveries(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	query, err := parseEventQuery(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Fetch one extra delivery to find out whether another page follows.
+	opts := DeliveryListOptions{WebhookID: id, Limit: query.Limit + 1, Before: query.Before}
+
+	var deliveries []WebhookDelivery
+	err = TrackDBOperation(c.Request.Context(), "query_webhook_deliveries", func(ctx context.Context) error {
+		if _, err := s.webhooks.GetWebhook(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		deliveries, err = s.webhooks.ListDeliveries(ctx, opts)
+		return err
+	})
+
+	if err != nil {
+		writeWebhookError(c, err, "failed to fetch deliveries")
+		return
+	}
+
+	if len(deliveries) > query.Limit {
+		deliveries = deliveries[:query.Limit]
+		setNextLink(c, encodeEventCursor(deliveries[query.Limit-1].ID))
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// workspaceKey is the context key holding the workspace of a request.
+type workspaceKey struct{}
+
+// WithWorkspace returns a copy of ctx limited to a workspace: stores only
+// read, change and delete its rows, and create rows in it. Contexts
+// without a workspace, used when authentication is disabled and by
+// maintenance jobs, see every workspace.
+func WithWorkspace(ctx context.Context, workspaceID uint) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceID)
+}
+
+// workspaceFrom returns the workspace ctx is limited to, if any.
+func workspaceFrom(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(workspaceKey{}).(uint)
+	return id, ok
+}
+
+// inWorkspace reports whether a row of the given workspace is visible
+// through ctx.
+func inWorkspace(ctx context.Context, workspaceID uint) bool {
+	id, ok := workspaceFrom(ctx)
+	return !ok || id == workspaceID
+}
+
+// workspaceScope is a GORM plugin enforcing WithWorkspace for every model
+// with a WorkspaceID field. Queries, updates and deletes are restricted to
+// the workspace of the statement's context, and created rows are placed
+// in it. Raw SQL is not rewritten and must filter on workspace_id itself.
+type workspaceScope struct{}
+
+// Name implements gorm.Plugin.
+func (workspaceScope) Name() string {
+	return "taskboard:workspace"
+}
+
+// Initialize implements gorm.Plugin.
+func (workspaceScope) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("workspace:create", setWorkspace),
+		callbacks.Query().Before("gorm:query").Register("workspace:query", whereWorkspace),
+		callbacks.Update().Before("gorm:update").Register("workspace:update", whereWorkspace),
+		callbacks.Delete().Before("gorm:delete").Register("workspace:delete", whereWorkspace),
+		callbacks.Row().Before("gorm:row").Register("workspace:row", whereWorkspace),
+	} {
+		if err != nil {
+			return fmt.Errorf("register workspace scope: %w", err)
+		}
+	}
+	return nil
+}
+
+// scopedWorkspace returns the workspace a statement is limited to, when
+// its model belongs to workspaces.
+func scopedWorkspace(db *gorm.DB) (uint, bool) {
+	if db.Statement.Schema == nil || db.Statement.Schema.LookUpField("WorkspaceID") == nil {
+		return 0, false
+	}
+	return workspaceFrom(db.Statement.Context)
+}
+
+// whereWorkspace restricts a statement to the rows of its workspace.
+func whereWorkspace(db *gorm.DB) {
+	if id, ok := scopedWorkspace(db); ok {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "workspace_id"}, Value: id},
+		}})
+	}
+}
+
+// setWorkspace places created rows in the statement's workspace.
+func setWorkspace(db *gorm.DB) {
+	if id, ok := scopedWorkspace(db); ok {
+		db.Statement.SetColumn("WorkspaceID", id, true)
+	}
+}
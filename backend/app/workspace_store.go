@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrWorkspaceNotFound is returned when no workspace matches the requested ID.
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
+// WorkspaceStore persists workspaces.
+type WorkspaceStore interface {
+	GetWorkspace(ctx context.Context, id uint) (*Workspace, error)
+	// CreateWorkspace creates a workspace together with its first user.
+	CreateWorkspace(ctx context.Context, workspace *Workspace, user *User) error
+	UpdateWorkspace(ctx context.Context, workspace *Workspace) error
+}
+
+// GormWorkspaceStore is a WorkspaceStore backed by a GORM database connection.
+type GormWorkspaceStore struct {
+	db *gorm.DB
+}
+
+// NewGormWorkspaceStore returns a WorkspaceStore that persists workspaces
+// through db.
+func NewGormWorkspaceStore(db *gorm.DB) *GormWorkspaceStore {
+	return &GormWorkspaceStore{db: db}
+}
+
+// GetWorkspace returns the workspace with the given ID.
+func (s *GormWorkspaceStore) GetWorkspace(ctx context.Context, id uint) (*Workspace, error) {
+	var workspace Workspace
+	err := dbFor(ctx, s.db).First(&workspace, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// CreateWorkspace inserts a workspace and its first user in one
+// transaction. The workspace is new, so its first user's email cannot be
+// taken.
+func (s *GormWorkspaceStore) CreateWorkspace(ctx context.Context, workspace *Workspace, user *User) error {
+	return dbFor(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		user.WorkspaceID = workspace.ID
+		// The new user belongs to the new workspace, not to the one of
+		// the context, if any.
+		return userError(tx.WithContext(WithWorkspace(ctx, workspace.ID)).Create(user).Error)
+	})
+}
+
+// UpdateWorkspace saves all fields of an existing workspace.
+func (s *GormWorkspaceStore) UpdateWorkspace(ctx context.Context, workspace *Workspace) error {
+	return dbFor(ctx, s.db).Save(workspace).Error
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeWorkspaceError maps WorkspaceStore errors to HTTP responses.
+func writeWorkspaceError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		writeError(c, http.StatusNotFound, "workspace not found")
+		return
+	}
+	writeInternalError(c, err, fallback)
+}
+
+// getWorkspace returns the workspace of the authenticated user.
+func (s *Server) getWorkspace(c *gin.Context) {
+	identity, _ := currentIdentity(c)
+
+	var workspace *Workspace
+	err := TrackDBOperation(c.Request.Context(), "find_workspace", func(ctx context.Context) error {
+		var err error
+		workspace, err = s.workspaces.GetWorkspace(ctx, identity.WorkspaceID)
+		return err
+	})
+
+	if err != nil {
+		writeWorkspaceError(c, err, "failed to fetch workspace")
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspaceInput represents the fields that can be updated in a
+// workspace.
+type UpdateWorkspaceInput struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=100"`
+}
+
+// updateWorkspace renames the workspace of the authenticated user.
+func (s *Server) updateWorkspace(c *gin.Context) {
+	identity, _ := currentIdentity(c)
+
+	var input UpdateWorkspaceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInputError(c, err)
+		return
+	}
+
+	var workspace *Workspace
+	err := TrackDBOperation(c.Request.Context(), "update_workspace", func(ctx context.Context) error {
+		var err error
+		if workspace, err = s.workspaces.GetWorkspace(ctx, identity.WorkspaceID); err != nil {
+			return err
+		}
+		if input.Name != nil {
+			workspace.Name = *input.Name
+		}
+		return s.workspaces.UpdateWorkspace(ctx, workspace)
+	})
+
+	if err != nil {
+		writeWorkspaceError(c, err, "failed to update workspace")
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// wsWriteTimeout bounds each write to a WebSocket client, so that a peer
+// that stopped reading cannot hold up its connection.
+const wsWriteTimeout = 10 * time.Second
+
+// wsMaxMessage is the size of the largest message a WebSocket client may
+// send, in bytes.
+const wsMaxMessage = 4 << 10
+
+// Types of WSMessage besides the task events.
+const (
+	wsPresence = "presence"
+	wsError    = "error"
+)
+
+// WSRequest is a message from a client of /api/ws.
+type WSRequest struct {
+	// Type is subscribe, unsubscribe or presence.
+	Type    string `json:"type"`
+	BoardID uint   `json:"board_id"`
+	// Editing is the task the user is editing, for presence requests.
+	Editing *uint `json:"editing"`
+}
+
+// WSMessage is a message to a client of /api/ws: a task event as in
+// GET /events, the users on a board, or an error answering a request.
+type WSMessage struct {
+	Type    string `json:"type"`
+	BoardID uint   `json:"board_id,omitempty"`
+	// EventID numbers task events like the IDs of GET /events.
+	EventID string         `json:"event_id,omitempty"`
+	Task    *Task          `json:"task,omitempty"`
+	Users   []PresenceUser `json:"users,omitempty"`
+	Detail  string         `json:"detail,omitempty"`
+}
+
+// wsClient is a connection to /api/ws.
+type wsClient struct {
+	// ctx is the context of the handshake, carrying the workspace.
+	ctx    context.Context
+	userID uint
+	name   string
+	// send holds the messages waiting to be written. It is bounded so that
+	// a client that falls behind is disconnected rather than holding up
+	// the others.
+	send      chan WSMessage
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// enqueue queues msg for the client, disconnecting it when its queue is
+// full.
+func (c *wsClient) enqueue(msg WSMessage) {
+	select {
+	case c.send <- msg:
+	default:
+		c.close()
+	}
+}
+
+// close disconnects the client. Closing it twice is not an error.
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// serveWS upgrades the request to a WebSocket on which clients follow
+// boards. After {"type":"subscribe","board_id":1}, a client receives the
+// task events of the board and its presence: the users following it,
+// each with the task they are editing as set by
+// {"type":"presence","board_id":1,"editing":42}. Clients that fall behind
+// are disconnected; they may reconnect and reload the board.
+func (s *Server) serveWS(c *gin.Context) {
+	if !strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		c.Header("Upgrade", "websocket")
+		writeError(c, http.StatusUpgradeRequired, "expected a WebSocket handshake")
+		return
+	}
+
+	client := &wsClient{
+		ctx:  c.Request.Context(),
+		send: make(chan WSMessage, subscriberBuffer),
+		done: make(chan struct{}),
+	}
+	if identity, ok := currentIdentity(c); ok {
+		client.userID, client.name = identity.UserID, identity.Name
+	}
+
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, _ *http.Request) error {
+			// Accept the subprotocol carrying the access token, if any.
+			if len(config.Protocol) > 0 && config.Protocol[0] == wsBearerProtocol {
+				config.Protocol = []string{wsBearerProtocol}
+			} else {
+				config.Protocol = nil
+			}
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) { s.runWSClient(client, conn) },
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// runWSClient writes the messages of client until it disconnects, falls
+// behind, or the hub closes.
+func (s *Server) runWSClient(client *wsClient, conn *websocket.Conn) {
+	defer conn.Close()
+	conn.MaxPayloadBytes = wsMaxMessage
+	defer trackConnectedClient(client.ctx, "websocket")()
+
+	sub, _, _ := s.hub.Subscribe(client.ctx, "")
+	defer s.hub.Unsubscribe(sub)
+	defer s.presence.leaveAll(client)
+
+	go s.readWS(client, conn)
+
+	for {
+		var msg WSMessage
+		select {
+		case <-client.done:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			boardID, ok := s.presence.following(client, event.BoardIDs)
+			if !ok {
+				continue
+			}
+			msg = WSMessage{Type: event.Type, BoardID: boardID, EventID: event.ID, Task: event.Task}
+		case msg = <-client.send:
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := websocket.JSON.Send(conn, msg); err != nil {
+			return
+		}
+	}
+}
+
+// readWS handles the requests of client until its connection fails.
+func (s *Server) readWS(client *wsClient, conn *websocket.Conn) {
+	defer client.close()
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+
+		var req WSRequest
+		err := json.Unmarshal(data, &req)
+		if err == nil {
+			err = s.handleWSRequest(client, req)
+		} else {
+			err = errors.New("invalid message: " + err.Error())
+		}
+		if err != nil {
+			client.enqueue(WSMessage{Type: wsError, BoardID: req.BoardID, Detail: err.Error()})
+		}
+	}
+}
+
+// handleWSRequest applies a request of client. The returned errors are
+// safe to show to the client.
+func (s *Server) handleWSRequest(client *wsClient, req WSRequest) error {
+	switch req.Type {
+	case "subscribe":
+		if err := s.checkWSBoard(client, req.BoardID); err != nil {
+			return err
+		}
+		s.presence.join(req.BoardID, client)
+	case "unsubscribe":
+		s.presence.leave(req.BoardID, client)
+	case "presence":
+		if req.Editing != nil {
+			if err := s.checkWSTask(client, req.BoardID, *req.Editing); err != nil {
+				return err
+			}
+		}
+		if !s.presence.edit(req.BoardID, client, req.Editing) {
+			return errors.New("not subscribed to the board")
+		}
+	default:
+		return errors.New("unknown message type " + req.Type)
+	}
+	return nil
+}
+
+// checkWSBoard checks that a board exists in the workspace of client. As
+// with GET /boards/:id, every user may follow every board.
+func (s *Server) checkWSBoard(client *wsClient, boardID uint) error {
+	if s.boards == nil {
+		return errors.New("boards are not available")
+	}
+
+	err := TrackDBOperation(client.ctx, "find_board", func(ctx context.Context) error {
+		_, err := s.boards.GetBoard(ctx, boardID)
+		return err
+	})
+
+	switch {
+	case errors.Is(err, ErrBoardNotFound):
+		return errors.New("board not found")
+	case err != nil:
+		log.Printf("Failed to fetch board %d: %v", boardID, err)
+		return errors.New("failed to fetch board")
+	}
+	return nil
+}
+
+// checkWSTask checks that a task is on a board.
+func (s *Server) checkWSTask(client *wsClient, boardID, taskID uint) error {
+	var task *Task
+	err := TrackDBOperation(client.ctx, "find_task", func(ctx context.Context) error {
+		var err error
+		task, err = s.tasks.Get(ctx, taskID)
+		return err
+	})
+
+	switch {
+	case errors.Is(err, ErrTaskNotFound) || err == nil && (task.BoardID == nil || *task.BoardID != boardID):
+		return errors.New("task not found on the board")
+	case err != nil:
+		log.Printf("Failed to fetch task %d: %v", taskID, err)
+		return errors.New("failed to fetch task")
+	}
+	return nil
+}
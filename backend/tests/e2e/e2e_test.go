@@ -1,15 +1,51 @@
 package e2e
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
+const baseURL = "http://localhost:8080"
+
 func TestHealthEndpoint(t *testing.T) {
-	resp, err := http.Get("http://localhost:8080/api/tasks")
+	resp, err := http.Get(baseURL + "/api/tasks")
+	if err != nil {
+		t.Fatalf("failed to reach backend: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+
+	body := fmt.Sprintf(`{"name":"E2E","email":"e2e-%d@example.com","password":"e2e-password"}`, time.Now().UnixNano())
+	resp, err = http.Post(baseURL+"/api/auth/register", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 registering, got %d", resp.StatusCode)
+	}
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		t.Fatalf("failed to decode tokens: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", baseURL+"/api/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	resp, err = http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("failed to reach backend: %v", err)
 	}
+	resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"taskboard-backend/app"
+)
+
+func TestTaskHistory(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	ada, tokens := registerUser(t, r, "Ada")
+	token := tokens.AccessToken
+
+	var board app.Board
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/boards", `{"name":"Sprint"}`, token), &board)
+	task := decodeTask(t, doAuthRequest(r, "POST", "/api/tasks", `{"title":"Draft"}`, token))
+	taskPath := fmt.Sprintf("/api/tasks/%d", task.ID)
+
+	w := doAuthRequest(r, "PATCH", taskPath, `{"title":"Final"}`, token)
+	requestID := w.Header().Get("X-Request-ID")
+	if requestID == "" {
+		t.Fatal("expected an X-Request-ID response header")
+	}
+	// Saving unchanged fields records nothing.
+	doAuthRequest(r, "PATCH", taskPath, `{"title":"Final"}`, token)
+	doAuthRequest(r, "POST", taskPath+"/move", fmt.Sprintf(`{"column_id":%d}`, board.Columns[1].ID), token)
+	doAuthRequest(r, "DELETE", taskPath, "", token)
+	doAuthRequest(r, "POST", taskPath+"/restore", "", token)
+
+	var events []app.TaskEvent
+	decodeJSON(t, doAuthRequest(r, "GET", taskPath+"/history", "", token), &events)
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.TaskID != task.ID || event.ActorID == nil || *event.ActorID != ada.ID {
+			t.Errorf("expected %s by Ada on task %d, got %+v", event.Type, task.ID, event)
+		}
+	}
+	want := "task.restored task.deleted task.updated task.updated task.created"
+	if got := strings.Join(types, " "); got != want {
+		t.Fatalf("expected events %q, got %q", want, got)
+	}
+
+	rename := events[3]
+	if rename.RequestID != requestID || len(rename.Changes) != 1 {
+		t.Fatalf("expected one change recorded for request %s, got %+v", requestID, rename)
+	}
+	if c := rename.Changes[0]; c.Field != "title" || string(c.Before) != `"Draft"` || string(c.After) != `"Final"` {
+		t.Fatalf("expected the title change, got %s: %s -> %s", c.Field, c.Before, c.After)
+	}
+	move := events[2]
+	if len(move.Changes) != 2 || move.Changes[0].Field != "board_id" || string(move.Changes[0].Before) != "null" {
+		t.Fatalf("expected the move onto the board, got %+v", move.Changes)
+	}
+	for _, c := range events[1].Changes {
+		if string(c.After) != "null" {
+			t.Fatalf("expected a deletion to clear every field, got %s -> %s", c.Field, c.After)
+		}
+	}
+
+	if w := doAuthRequest(r, "GET", "/api/tasks/999/history", "", token); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown task, got %d", w.Code)
+	}
+}
+
+func TestActivityFeed(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, ada := registerUser(t, r, "Ada")
+	_, zed := registerUser(t, r, "Zed")
+
+	for i := 0; i < 3; i++ {
+		doAuthRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Task %d"}`, i), ada.AccessToken)
+	}
+	doAuthRequest(r, "POST", "/api/tasks", `{"title":"Elsewhere"}`, zed.AccessToken)
+
+	// A rolled back batch leaves no trace.
+	w := doAuthRequest(r, "POST", "/api/tasks/bulk", `{"operations":[{"op":"create","task":{"title":"Ghost"}},{"op":"delete","id":0}]}`, ada.AccessToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected the batch to fail, got %d", w.Code)
+	}
+
+	w = doAuthRequest(r, "GET", "/api/activity?limit=2", "", ada.AccessToken)
+	var page []app.TaskEvent
+	decodeJSON(t, w, &page)
+	link := w.Header().Get("Link")
+	if len(page) != 2 || link == "" {
+		t.Fatalf("expected a first page of 2 events and a Link header, got %d %q", len(page), link)
+	}
+	next := strings.TrimPrefix(strings.SplitN(link, ">", 2)[0], "<")
+
+	w = doAuthRequest(r, "GET", next, "", ada.AccessToken)
+	var rest []app.TaskEvent
+	decodeJSON(t, w, &rest)
+	if len(rest) != 1 || w.Header().Get("Link") != "" || rest[0].ID >= page[1].ID {
+		t.Fatalf("expected one older event on the last page, got %+v", rest)
+	}
+
+	if w := doAuthRequest(r, "GET", "/api/activity?cursor=bogus", "", ada.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid cursor, got %d", w.Code)
+	}
+}
+
+func TestColumnDoneHistory(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Sprint"}`), &board)
+	review := board.Columns[1]
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Draft","column_id":%d}`, review.ID)))
+
+	// Flagging the column as done completes its tasks, on the record.
+	if w := doRequest(r, "PUT", fmt.Sprintf("/api/boards/%d/columns/%d", board.ID, review.ID), `{"done":true}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var events []app.TaskEvent
+	decodeJSON(t, doRequest(r, "GET", fmt.Sprintf("/api/tasks/%d/history", task.ID), ""), &events)
+	if len(events) != 2 || events[0].Type != app.TaskUpdated || len(events[0].Changes) != 1 ||
+		events[0].Changes[0].Field != "completed" || string(events[0].Changes[0].After) != "true" {
+		t.Fatalf("expected the completion recorded, got %+v", events)
+	}
+}
+
+func TestDeleteBoardHistory(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Sprint"}`), &board)
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Draft","column_id":%d}`, board.Columns[0].ID)))
+
+	// Deleting the board takes its tasks off it, on the record.
+	if w := doRequest(r, "DELETE", fmt.Sprintf("/api/boards/%d", board.ID), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	var events []app.TaskEvent
+	decodeJSON(t, doRequest(r, "GET", fmt.Sprintf("/api/tasks/%d/history", task.ID), ""), &events)
+	if len(events) != 2 || events[0].Type != app.TaskUpdated {
+		t.Fatalf("expected the detachment recorded, got %+v", events)
+	}
+	fields := map[string]string{}
+	for _, change := range events[0].Changes {
+		fields[change.Field] = string(change.After)
+	}
+	if fields["board_id"] != "null" || fields["column_id"] != "null" {
+		t.Fatalf("expected the board and column cleared, got %+v", events[0].Changes)
+	}
+}
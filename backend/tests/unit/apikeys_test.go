@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"taskboard-backend/app"
+)
+
+// createAPIKey issues an API key through the API using token.
+func createAPIKey(t *testing.T, r http.Handler, token, body string) app.CreatedAPIKey {
+	t.Helper()
+	w := doAuthRequest(r, "POST", "/api/keys", body, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var key app.CreatedAPIKey
+	decodeJSON(t, w, &key)
+	return key
+}
+
+// doKeyRequest sends a request authenticated with key in the X-API-Key
+// header.
+func doKeyRequest(r http.Handler, method, path, body, key string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-API-Key", key)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeys(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, tokens := registerUser(t, r, "Ada")
+
+	key := createAPIKey(t, r, tokens.AccessToken, `{"name":"ci"}`)
+	if !strings.HasPrefix(key.Key, "tbk_") || !strings.HasPrefix(key.Key, key.Prefix) {
+		t.Fatalf("unexpected key %q with prefix %q", key.Key, key.Prefix)
+	}
+
+	// Keys work in both headers.
+	if w := doKeyRequest(r, "POST", "/api/tasks", `{"title":"From CI"}`, key.Key); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 with X-API-Key, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", key.Key); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a bearer API key, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doKeyRequest(r, "GET", "/api/tasks", "", key.Key+"x"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an unknown key, got %d", w.Code)
+	}
+
+	// The listing never exposes the secret but shows when it was used.
+	w := doAuthRequest(r, "GET", "/api/keys", "", tokens.AccessToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), key.Key) {
+		t.Fatal("listing exposes the key")
+	}
+	var keys []app.APIKey
+	decodeJSON(t, w, &keys)
+	if len(keys) != 1 || keys[0].Prefix != key.Prefix || keys[0].LastUsedAt == nil {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	// Keys are private to their owner.
+	_, other := registerUser(t, r, "Bo")
+	if w := doAuthRequest(r, "DELETE", fmt.Sprintf("/api/keys/%d", key.ID), "", other.AccessToken); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 revoking another user's key, got %d", w.Code)
+	}
+
+	if w := doAuthRequest(r, "DELETE", fmt.Sprintf("/api/keys/%d", key.ID), "", tokens.AccessToken); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := doKeyRequest(r, "GET", "/api/tasks", "", key.Key); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a revoked key, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, tokens := registerUser(t, r, "Ada")
+
+	readOnly := createAPIKey(t, r, tokens.AccessToken, `{"name":"dashboard","scopes":["tasks:read"]}`)
+	if w := doKeyRequest(r, "GET", "/api/tasks", "", readOnly.Key); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w := doKeyRequest(r, "POST", "/api/tasks", `{"title":"Nope"}`, readOnly.Key); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 writing with a read-only key, got %d", w.Code)
+	}
+
+	writer := createAPIKey(t, r, tokens.AccessToken, `{"name":"bot","scopes":["tasks:read","tasks:write"]}`)
+	if w := doKeyRequest(r, "POST", "/api/tasks", `{"title":"Yes"}`, writer.Key); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+
+	// Scoped keys cannot manage keys or create more powerful ones.
+	if w := doKeyRequest(r, "POST", "/api/keys", `{"name":"escalate"}`, writer.Key); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 creating a key with a scoped key, got %d", w.Code)
+	}
+	if w := doKeyRequest(r, "POST", "/api/labels", `{"name":"bug"}`, writer.Key); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 managing labels with a scoped key, got %d", w.Code)
+	}
+
+	if w := doAuthRequest(r, "POST", "/api/keys", `{"name":"bad","scopes":["admin"]}`, tokens.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown scope, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, tokens := registerUser(t, r, "Ada")
+
+	past := `{"name":"old","expires_at":"` + time.Now().Add(-time.Hour).Format(time.RFC3339) + `"}`
+	if w := doAuthRequest(r, "POST", "/api/keys", past, tokens.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a past expiry, got %d", w.Code)
+	}
+
+	soon := time.Now().Add(time.Second).Format(time.RFC3339Nano)
+	key := createAPIKey(t, r, tokens.AccessToken, `{"name":"short","expires_at":"`+soon+`"}`)
+	if w := doKeyRequest(r, "GET", "/api/tasks", "", key.Key); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 before expiry, got %d", w.Code)
+	}
+	time.Sleep(time.Until(*key.ExpiresAt))
+	if w := doKeyRequest(r, "GET", "/api/tasks", "", key.Key); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after expiry, got %d", w.Code)
+	}
+}
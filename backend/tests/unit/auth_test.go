@@ -0,0 +1,221 @@
+package unit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"taskboard-backend/app"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func testAuthConfig() app.AuthConfig {
+	return app.AuthConfig{
+		Secret:     testSecret,
+		Issuer:     "taskboard-test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	}
+}
+
+// newAuthRouter returns a SQLite backed router that requires tokens
+// issued with cfg.
+func newAuthRouter(t *testing.T, cfg app.AuthConfig) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	auth, err := app.NewAuthenticator(cfg)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server := app.NewServer(app.NewGormStores(newSQLiteDB(t)), auth)
+	startRelay(t, server)
+	return server.Router()
+}
+
+func doAuthRequest(r http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// registerUser signs up a user named name and returns it with its tokens.
+func registerUser(t *testing.T, r http.Handler, name string) (app.User, app.TokenPair) {
+	t.Helper()
+	body := fmt.Sprintf(`{"name":%q,"email":"%s@example.com","password":"correct horse"}`, name, strings.ToLower(name))
+	w := doRequest(r, "POST", "/api/auth/register", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var tokens app.TokenPair
+	decodeJSON(t, w, &tokens)
+
+	var user app.User
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/me", "", tokens.AccessToken), &user)
+	return user, tokens
+}
+
+// addTeammate creates a user named name in the workspace of token and
+// logs them in.
+func addTeammate(t *testing.T, r http.Handler, token, name string) (app.User, app.TokenPair) {
+	t.Helper()
+	email := strings.ToLower(name) + "@example.com"
+	body := fmt.Sprintf(`{"name":%q,"email":%q,"password":"correct horse"}`, name, email)
+	w := doAuthRequest(r, "POST", "/api/users", body, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var user app.User
+	decodeJSON(t, w, &user)
+
+	w = doRequest(r, "POST", "/api/auth/login", fmt.Sprintf(`{"email":%q,"password":"correct horse"}`, email))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var tokens app.TokenPair
+	decodeJSON(t, w, &tokens)
+	return user, tokens
+}
+
+func TestAuthentication(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+
+	if w := doRequest(r, "GET", "/api/tasks", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", w.Code)
+	}
+
+	user, tokens := registerUser(t, r, "Ana")
+	if user.Email != "ana@example.com" || tokens.TokenType != "Bearer" {
+		t.Fatalf("unexpected registration result: %+v %+v", user, tokens)
+	}
+
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", tokens.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a token, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", tokens.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a refresh token used as access token, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", tokens.AccessToken+"x"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a tampered token, got %d", w.Code)
+	}
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": "taskboard-test", "sub": fmt.Sprint(user.ID), "typ": "access", "wid": user.WorkspaceID, "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", unsigned); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an unsigned token, got %d", w.Code)
+	}
+
+	w := doRequest(r, "POST", "/api/auth/login", `{"email":"ANA@example.com","password":"correct horse"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 logging in, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(r, "POST", "/api/auth/login", `{"email":"ana@example.com","password":"wrong"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a wrong password, got %d", w.Code)
+	}
+
+	w = doRequest(r, "POST", "/api/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, tokens.RefreshToken))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 refreshing, got %d: %s", w.Code, w.Body.String())
+	}
+	var refreshed app.TokenPair
+	decodeJSON(t, w, &refreshed)
+	if w := doAuthRequest(r, "GET", "/api/me", "", refreshed.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected refreshed token to work, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/api/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, tokens.AccessToken)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 refreshing with an access token, got %d", w.Code)
+	}
+
+	other, _ := registerUser(t, r, "Bo")
+	if w := doAuthRequest(r, "DELETE", fmt.Sprintf("/api/users/%d", other.ID), "", tokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 deleting another user, got %d", w.Code)
+	}
+}
+
+func TestExpiredToken(t *testing.T) {
+	cfg := testAuthConfig()
+	cfg.AccessTTL = time.Nanosecond
+	r := newAuthRouter(t, cfg)
+
+	_, tokens := registerUser(t, r, "Ana")
+	w := doAuthRequest(r, "GET", "/api/tasks", "", tokens.AccessToken)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "expired") {
+		t.Fatalf("expected 401 for an expired token, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRS256Tokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "jwt.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testAuthConfig()
+	cfg.KeyFile = keyFile
+	r := newAuthRouter(t, cfg)
+
+	_, tokens := registerUser(t, r, "Ana")
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", tokens.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with an RS256 token, got %d", w.Code)
+	}
+
+	// A token signed with the shared secret must not be accepted.
+	hs := newAuthRouter(t, testAuthConfig())
+	_, hsTokens := registerUser(t, hs, "Ana")
+	if w := doAuthRequest(r, "GET", "/api/tasks", "", hsTokens.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an HS256 token, got %d", w.Code)
+	}
+}
+
+func TestCommentOwnership(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+
+	ana, anaTokens := registerUser(t, r, "Ana")
+	_, boTokens := addTeammate(t, r, anaTokens.AccessToken, "Bo")
+
+	doAuthRequest(r, "POST", "/api/tasks", `{"title":"Review"}`, anaTokens.AccessToken)
+	w := doAuthRequest(r, "POST", "/api/tasks/1/comments", `{"body":"Looks good"}`, anaTokens.AccessToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var comment app.Comment
+	decodeJSON(t, w, &comment)
+	if comment.AuthorID == nil || *comment.AuthorID != ana.ID || comment.Author != "Ana" {
+		t.Fatalf("expected comment attributed to Ana, got %+v", comment)
+	}
+
+	path := fmt.Sprintf("/api/tasks/1/comments/%d", comment.ID)
+	if w := doAuthRequest(r, "PUT", path, `{"body":"Hijacked"}`, boTokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 editing someone else's comment, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "DELETE", path, "", boTokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 deleting someone else's comment, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "DELETE", path, "", anaTokens.AccessToken); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 deleting own comment, got %d", w.Code)
+	}
+}
@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"taskboard-backend/app"
+)
+
+// newSQLiteRouter returns a router backed by a private in-memory SQLite database.
+func newSQLiteRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	server := app.NewServer(app.NewGormStores(newSQLiteDB(t)), nil)
+	startRelay(t, server)
+	return server.Router()
+}
+
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("failed to decode response: %v (body %s)", err, w.Body.String())
+	}
+}
+
+func listColumnTasks(t *testing.T, r http.Handler, columnID uint) []app.Task {
+	t.Helper()
+	var tasks []app.Task
+	decodeJSON(t, doRequest(r, "GET", fmt.Sprintf("/api/tasks?column_id=%d&sort=position", columnID), ""), &tasks)
+	return tasks
+}
+
+func TestBoardLifecycle(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	w := doRequest(r, "POST", "/api/boards", `{"name":"Sprint 1"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var board app.Board
+	decodeJSON(t, w, &board)
+	if len(board.Columns) != 3 || !board.Columns[2].Done {
+		t.Fatalf("expected default columns, got %+v", board.Columns)
+	}
+
+	w = doRequest(r, "POST", fmt.Sprintf("/api/boards/%d/columns", board.ID), `{"name":"Review"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var review app.Column
+	decodeJSON(t, w, &review)
+	if review.Position != 3 {
+		t.Fatalf("expected new column appended at position 3, got %d", review.Position)
+	}
+
+	w = doRequest(r, "PUT", fmt.Sprintf("/api/boards/%d/columns/%d", board.ID, review.ID), `{"name":"Code review"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if w := doRequest(r, "DELETE", fmt.Sprintf("/api/boards/%d/columns/%d", board.ID, review.ID), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	var columns []app.Column
+	decodeJSON(t, doRequest(r, "GET", fmt.Sprintf("/api/boards/%d/columns", board.ID), ""), &columns)
+	if len(columns) != 3 {
+		t.Fatalf("expected 3 columns after delete, got %+v", columns)
+	}
+
+	if w := doRequest(r, "GET", "/api/boards/999/columns", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown board, got %d", w.Code)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Board"}`), &board)
+	todo, done := board.Columns[0], board.Columns[2]
+
+	for i := 1; i <= 3; i++ {
+		doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Task %d"}`, i))
+		w := doRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/move", i), fmt.Sprintf(`{"column_id":%d,"position":%d}`, todo.ID, i))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	// Move the last task to the top of the same column.
+	doRequest(r, "POST", "/api/tasks/3/move", fmt.Sprintf(`{"column_id":%d,"position":0}`, todo.ID))
+
+	tasks := listColumnTasks(t, r, todo.ID)
+	if len(tasks) != 3 || tasks[0].ID != 3 || tasks[1].ID != 1 || tasks[2].ID != 2 {
+		t.Fatalf("unexpected order after reorder: %+v", tasks)
+	}
+	for i, task := range tasks {
+		if task.Position != i {
+			t.Fatalf("expected contiguous positions, got %+v", tasks)
+		}
+	}
+
+	// Moving into the done column completes the task.
+	w := doRequest(r, "POST", "/api/tasks/1/move", fmt.Sprintf(`{"column_id":%d,"position":0}`, done.ID))
+	moved := decodeTask(t, w)
+	if !moved.Completed || moved.ColumnID == nil || *moved.ColumnID != done.ID {
+		t.Fatalf("expected task completed in done column, got %+v", moved)
+	}
+
+	tasks = listColumnTasks(t, r, todo.ID)
+	if len(tasks) != 2 || tasks[0].Position != 0 || tasks[1].Position != 1 {
+		t.Fatalf("expected gap closed in source column, got %+v", tasks)
+	}
+
+	// Reopening the task through PATCH moves it back out of the done column.
+	updated := decodeTask(t, doRequest(r, "PATCH", "/api/tasks/1", `{"completed":false}`))
+	if updated.Completed || *updated.ColumnID != todo.ID || updated.Position != 2 {
+		t.Fatalf("expected task reopened at the end of the first column, got %+v", updated)
+	}
+
+	if w := doRequest(r, "DELETE", fmt.Sprintf("/api/boards/%d/columns/%d", board.ID, todo.ID), ""); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 deleting a non-empty column, got %d", w.Code)
+	}
+
+	if w := doRequest(r, "POST", "/api/tasks/1/move", `{"column_id":999}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown column, got %d", w.Code)
+	}
+
+	if w := doRequest(r, "DELETE", fmt.Sprintf("/api/boards/%d", board.ID), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	task := decodeTask(t, doRequest(r, "GET", "/api/tasks/1", ""))
+	if task.BoardID != nil || task.ColumnID != nil {
+		t.Fatalf("expected task detached from deleted board, got %+v", task)
+	}
+}
+
+// checkPositions fails unless tasks, in column order, are at the positions
+// 0 to n-1.
+func checkPositions(t *testing.T, tasks []app.Task) {
+	t.Helper()
+	for i, task := range tasks {
+		if task.Position != i {
+			t.Fatalf("expected contiguous positions, got %+v", tasks)
+		}
+	}
+}
+
+func TestDeleteTaskClosesGap(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Board"}`), &board)
+	todo := board.Columns[0]
+
+	var ids []uint
+	for i := 1; i <= 3; i++ {
+		task := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Task %d","column_id":%d}`, i, todo.ID)))
+		ids = append(ids, task.ID)
+	}
+	if w := doRequest(r, "DELETE", fmt.Sprintf("/api/tasks/%d", ids[0]), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	checkPositions(t, listColumnTasks(t, r, todo.ID))
+
+	// A new task still lands at the bottom of the column.
+	created := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Task 4","column_id":%d}`, todo.ID)))
+	tasks := listColumnTasks(t, r, todo.ID)
+	if len(tasks) != 3 || tasks[0].ID != ids[1] || tasks[1].ID != ids[2] || tasks[2].ID != created.ID {
+		t.Fatalf("expected the new task appended, got %+v", tasks)
+	}
+	checkPositions(t, tasks)
+}
@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"taskboard-backend/app"
+)
+
+func TestBulkTasks(t *testing.T) {
+	r := newSQLiteRouter(t)
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Cleanup"}`), &board)
+	todo, done := board.Columns[0].ID, board.Columns[len(board.Columns)-1].ID
+	for _, title := range []string{"Old", "Stale", "Keep"} {
+		doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":%q,"column_id":%d}`, title, todo))
+	}
+
+	w := doRequest(r, "POST", "/api/tasks/bulk", fmt.Sprintf(`{"operations":[
+		{"op":"create","task":{"title":"New","priority":"high"}},
+		{"op":"update","id":3,"patch":{"title":"Keep it"}},
+		{"op":"complete","id":2},
+		{"op":"delete","id":1},
+		{"op":"move","id":3,"column_id":%d,"position":0}
+	]}`, done))
+	var res app.BulkResponse
+	decodeJSON(t, w, &res)
+	if w.Code != http.StatusOK || !res.Committed || len(res.Results) != 5 {
+		t.Fatalf("expected the batch committed, got %d %s", w.Code, w.Body.String())
+	}
+	for i, status := range []int{201, 200, 200, 204, 200} {
+		if res.Results[i].Index != i || res.Results[i].Status != status || res.Results[i].Error != nil {
+			t.Errorf("operation %d: expected %d, got %+v", i, status, res.Results[i])
+		}
+	}
+	if created := res.Results[0].Task; created == nil || created.Title != "New" || created.Priority != app.PriorityHigh {
+		t.Errorf("expected the created task, got %+v", created)
+	}
+	if completed := res.Results[2].Task; completed == nil || !completed.Completed || *completed.ColumnID != done {
+		t.Errorf("expected the task completed into the done column, got %+v", completed)
+	}
+
+	tasks := listColumnTasks(t, r, done)
+	if len(tasks) != 2 || tasks[0].Title != "Keep it" || tasks[1].Title != "Stale" {
+		t.Fatalf("expected the moved and completed tasks in the done column, got %+v", tasks)
+	}
+	if w := doRequest(r, "GET", "/api/tasks/1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected the deleted task gone, got %d", w.Code)
+	}
+}
+
+func TestBulkTasksAtomic(t *testing.T) {
+	r := newSQLiteRouter(t)
+	doRequest(r, "POST", "/api/tasks", `{"title":"Keep"}`)
+
+	w := doRequest(r, "POST", "/api/tasks/bulk", `{"operations":[
+		{"op":"update","id":1,"patch":{"title":"Changed"}},
+		{"op":"create","task":{"title":"New"}},
+		{"op":"complete","id":42},
+		{"op":"delete","id":1}
+	]}`)
+	var res app.BulkResponse
+	decodeJSON(t, w, &res)
+	if w.Code != http.StatusNotFound || res.Committed {
+		t.Fatalf("expected the batch rolled back with 404, got %d %s", w.Code, w.Body.String())
+	}
+	if failed := res.Results[2]; failed.Status != http.StatusNotFound || failed.Error == nil || failed.Error.Detail != "task not found" {
+		t.Fatalf("expected the failed operation reported, got %+v", failed)
+	}
+	for _, i := range []int{0, 1, 3} {
+		if res.Results[i].Status != http.StatusFailedDependency || res.Results[i].Task != nil {
+			t.Errorf("operation %d: expected 424, got %+v", i, res.Results[i])
+		}
+	}
+
+	tasks := listTasksAs(t, r, "", "")
+	if len(tasks) != 1 || tasks[0].Title != "Keep" {
+		t.Fatalf("expected nothing applied, got %+v", tasks)
+	}
+}
+
+func TestBulkTasksBestEffort(t *testing.T) {
+	r := newSQLiteRouter(t)
+	doRequest(r, "POST", "/api/tasks", `{"title":"Keep"}`)
+
+	w := doRequest(r, "POST", "/api/tasks/bulk", `{"mode":"best_effort","operations":[
+		{"op":"update","id":1,"patch":{"title":""}},
+		{"op":"create","task":{"title":"New"}},
+		{"op":"update","id":1,"patch":{"description":"Changed"}},
+		{"op":"create"},
+		{"op":"archive","id":1}
+	]}`)
+	var res app.BulkResponse
+	decodeJSON(t, w, &res)
+	if w.Code != http.StatusOK || !res.Committed {
+		t.Fatalf("expected the batch committed, got %d %s", w.Code, w.Body.String())
+	}
+	for i, want := range map[int]app.FieldError{
+		0: {Field: "title", Message: "is required"},
+		3: {Field: "task", Message: "is required"},
+		4: {Field: "op", Message: "must be one of create, update, complete, delete, move"},
+	} {
+		got := res.Results[i]
+		if got.Status != http.StatusBadRequest || got.Error == nil || len(got.Error.Errors) != 1 || got.Error.Errors[0] != want {
+			t.Errorf("operation %d: expected 400 with %+v, got %+v", i, want, got)
+		}
+	}
+	for _, i := range []int{1, 2} {
+		if res.Results[i].Status != http.StatusCreated && res.Results[i].Status != http.StatusOK {
+			t.Errorf("operation %d: expected success, got %+v", i, res.Results[i])
+		}
+	}
+
+	tasks := listTasksAs(t, r, "", "sort=created_at&order=asc")
+	if len(tasks) != 2 || tasks[0].Title != "Keep" || tasks[0].Description != "Changed" || tasks[1].Title != "New" {
+		t.Fatalf("expected the valid operations applied, got %+v", tasks)
+	}
+
+	if w := doRequest(r, "POST", "/api/tasks/bulk", `{"operations":[]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an empty batch, got %d", w.Code)
+	}
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"taskboard-backend/app"
+)
+
+func listChecklist(t *testing.T, r *gin.Engine, taskID uint) []app.ChecklistItem {
+	t.Helper()
+	var items []app.ChecklistItem
+	decodeJSON(t, doRequest(r, "GET", fmt.Sprintf("/api/tasks/%d/checklist", taskID), ""), &items)
+	return items
+}
+
+func getTask(t *testing.T, r *gin.Engine, id uint) app.Task {
+	t.Helper()
+	var tasks []app.Task
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks", ""), &tasks)
+	for _, task := range tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	t.Fatalf("task %d not listed", id)
+	return app.Task{}
+}
+
+func TestChecklist(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Release"}`))
+	if task.Progress != nil {
+		t.Fatalf("expected no progress without a checklist, got %+v", task.Progress)
+	}
+
+	for _, title := range []string{"Build", "Test", "Tag"} {
+		w := doRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/checklist", task.ID), fmt.Sprintf(`{"title":%q}`, title))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	items := listChecklist(t, r, task.ID)
+	if len(items) != 3 || items[2].Title != "Tag" || items[2].Position != 2 {
+		t.Fatalf("expected items appended in order, got %+v", items)
+	}
+
+	// Move "Tag" to the top.
+	w := doRequest(r, "PUT", fmt.Sprintf("/api/tasks/%d/checklist/%d", task.ID, items[2].ID), `{"position":0}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	items = listChecklist(t, r, task.ID)
+	for i, want := range []string{"Tag", "Build", "Test"} {
+		if items[i].Title != want || items[i].Position != i {
+			t.Fatalf("unexpected order after reorder: %+v", items)
+		}
+	}
+
+	doRequest(r, "PUT", fmt.Sprintf("/api/tasks/%d/checklist/%d", task.ID, items[0].ID), `{"done":true}`)
+	if progress := getTask(t, r, task.ID).Progress; progress == nil || progress.Done != 1 || progress.Total != 3 {
+		t.Fatalf("expected 1/3 progress, got %+v", progress)
+	}
+
+	w = doRequest(r, "DELETE", fmt.Sprintf("/api/tasks/%d/checklist/%d", task.ID, items[1].ID), "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	items = listChecklist(t, r, task.ID)
+	if len(items) != 2 || items[1].Title != "Test" || items[1].Position != 1 {
+		t.Fatalf("expected gap closed after delete, got %+v", items)
+	}
+
+	if w := doRequest(r, "PUT", fmt.Sprintf("/api/tasks/%d/checklist/99", task.ID), `{"done":true}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown item, got %d", w.Code)
+	}
+	if w := doRequest(r, "GET", "/api/tasks/99/checklist", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown task, got %d", w.Code)
+	}
+
+	// Deleting the task removes its checklist.
+	doRequest(r, "DELETE", fmt.Sprintf("/api/tasks/%d", task.ID), "")
+	if w := doRequest(r, "GET", fmt.Sprintf("/api/tasks/%d/checklist", task.ID), ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after deleting the task, got %d", w.Code)
+	}
+}
+
+func TestChecklistAutoComplete(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	manual := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Manual"}`))
+	auto := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Auto","auto_complete":true}`))
+
+	for _, task := range []app.Task{manual, auto} {
+		for _, title := range []string{"one", "two"} {
+			doRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/checklist", task.ID), fmt.Sprintf(`{"title":%q}`, title))
+		}
+		for _, item := range listChecklist(t, r, task.ID) {
+			doRequest(r, "PUT", fmt.Sprintf("/api/tasks/%d/checklist/%d", task.ID, item.ID), `{"done":true}`)
+		}
+	}
+
+	if getTask(t, r, manual.ID).Completed {
+		t.Fatal("expected task without auto_complete to stay open")
+	}
+	if !getTask(t, r, auto.ID).Completed {
+		t.Fatal("expected task with auto_complete to be completed")
+	}
+
+	updated := decodeTask(t, doRequest(r, "PATCH", fmt.Sprintf("/api/tasks/%d", manual.ID), `{"auto_complete":true}`))
+	if !updated.Completed || updated.Progress == nil || updated.Progress.Done != 2 {
+		t.Fatalf("expected enabling auto_complete on a done checklist to complete the task, got %+v", updated)
+	}
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"taskboard-backend/app"
+)
+
+func TestComments(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Discuss API"}`))
+	path := fmt.Sprintf("/api/tasks/%d/comments", task.ID)
+
+	for _, body := range []string{`{"author":"ana","body":"First"}`, `{"author":"bo","body":"Second"}`} {
+		if w := doRequest(r, "POST", path, body); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if w := doRequest(r, "POST", path, `{"author":"ana"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without body, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/api/tasks/99/comments", `{"author":"ana","body":"x"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown task, got %d", w.Code)
+	}
+
+	var comments []app.Comment
+	decodeJSON(t, doRequest(r, "GET", path, ""), &comments)
+	if len(comments) != 2 || comments[0].Body != "First" || comments[0].EditedAt != nil {
+		t.Fatalf("expected comments oldest first, got %+v", comments)
+	}
+
+	if count := getTask(t, r, task.ID).CommentCount; count != 2 {
+		t.Fatalf("expected comment_count 2, got %d", count)
+	}
+
+	var edited app.Comment
+	decodeJSON(t, doRequest(r, "PUT", fmt.Sprintf("%s/%d", path, comments[0].ID), `{"body":"First, edited"}`), &edited)
+	if edited.Body != "First, edited" || edited.EditedAt == nil {
+		t.Fatalf("expected edited comment, got %+v", edited)
+	}
+
+	if w := doRequest(r, "DELETE", fmt.Sprintf("%s/%d", path, comments[1].ID), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := doRequest(r, "DELETE", fmt.Sprintf("%s/%d", path, comments[1].ID), ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 deleting twice, got %d", w.Code)
+	}
+	if count := getTask(t, r, task.ID).CommentCount; count != 1 {
+		t.Fatalf("expected comment_count 1, got %d", count)
+	}
+
+	// Deleting the task removes its thread.
+	doRequest(r, "DELETE", fmt.Sprintf("/api/tasks/%d", task.ID), "")
+	other := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Other"}`))
+	if count := getTask(t, r, other.ID).CommentCount; count != 0 {
+		t.Fatalf("expected no comments on a new task, got %d", count)
+	}
+	if w := doRequest(r, "GET", path, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after deleting the task, got %d", w.Code)
+	}
+}
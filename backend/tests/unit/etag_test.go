@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTaskETag(t *testing.T) {
+	r := newTestRouter()
+
+	w := doRequest(r, "POST", "/api/tasks", `{"title":"Toggle me"}`)
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf(`expected ETag "1", got %q`, etag)
+	}
+
+	w = doRequest(r, "GET", "/api/tasks/1", "")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` || decodeTask(t, w).Version != 1 {
+		t.Fatalf("expected version 1, got %d %q %s", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+	if w := doRequest(r, "GET", "/api/tasks/42", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	w = doRequestWithHeader(r, "PATCH", "/api/tasks/1", `{"completed":true}`, "If-Match", `"1"`)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected 200 with ETag \"2\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// A second client still holding version 1 loses the race.
+	w = doRequestWithHeader(r, "PATCH", "/api/tasks/1", `{"completed":false}`, "If-Match", `"1"`)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a stale ETag, got %d", w.Code)
+	}
+	if task := getTask(t, r, 1); !task.Completed || task.Version != 2 {
+		t.Fatalf("expected the first update to stick, got %+v", task)
+	}
+	if w := doRequestWithHeader(r, "PUT", "/api/tasks/1", `{"title":"Any"}`, "If-Match", "*"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for If-Match *, got %d", w.Code)
+	}
+
+	if w := doRequestWithHeader(r, "DELETE", "/api/tasks/1", "", "If-Match", `"2"`); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 deleting a stale version, got %d", w.Code)
+	}
+	if w := doRequestWithHeader(r, "DELETE", "/api/tasks/1", "", "If-Match", `"0", "3"`); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := doRequestWithHeader(r, "DELETE", "/api/tasks/1", "", "If-Match", "*"); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 deleting a missing task, got %d", w.Code)
+	}
+}
+
+func TestGetTasksNotModified(t *testing.T) {
+	r := newTestRouter()
+	doRequest(r, "POST", "/api/tasks", `{"title":"Cache me"}`)
+
+	w := doRequest(r, "GET", "/api/tasks", "")
+	etag := w.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected a weak ETag, got %q", etag)
+	}
+
+	w = doRequestWithHeader(r, "GET", "/api/tasks", "", "If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected 304 without a body, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Any change to the listed tasks invalidates the ETag.
+	doRequest(r, "PATCH", "/api/tasks/1", `{"completed":true}`)
+	w = doRequestWithHeader(r, "GET", "/api/tasks", "", "If-None-Match", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected 200 with a new ETag, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
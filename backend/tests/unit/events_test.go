@@ -0,0 +1,203 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"taskboard-backend/app"
+)
+
+// sseEvent is one event read from a Server-Sent Events stream.
+type sseEvent struct {
+	ID, Type, Data string
+}
+
+// eventStream is an open GET /api/events response.
+type eventStream struct {
+	body   *bufio.Reader
+	cancel context.CancelFunc
+}
+
+// openEventStream connects to the event stream of srv, resuming after
+// lastID unless it is empty.
+func openEventStream(t *testing.T, srv *httptest.Server, lastID string) *eventStream {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/api/events", nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("failed to open the event stream: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		cancel()
+		t.Fatalf("expected an event stream, got %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	stream := &eventStream{body: bufio.NewReader(res.Body), cancel: cancel}
+	t.Cleanup(stream.close)
+	return stream
+}
+
+func (s *eventStream) close() {
+	s.cancel()
+}
+
+// next returns the next event, skipping comments and retry hints.
+func (s *eventStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := s.body.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if event.Type != "" {
+				return event
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = value
+		case "data":
+			event.Data = value
+		}
+	}
+}
+
+func postTask(t *testing.T, srv *httptest.Server, title string) app.Task {
+	t.Helper()
+	res, err := srv.Client().Post(srv.URL+"/api/tasks", "application/json", strings.NewReader(`{"title":"`+title+`"}`))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	defer res.Body.Close()
+	var task app.Task
+	if err := json.NewDecoder(res.Body).Decode(&task); err != nil {
+		t.Fatalf("failed to decode task: %v", err)
+	}
+	return task
+}
+
+func TestEventStream(t *testing.T) {
+	srv := httptest.NewServer(newTestRouter())
+	// Registered first, so it runs after the streams are closed.
+	t.Cleanup(srv.Close)
+
+	stream := openEventStream(t, srv, "")
+	task := postTask(t, srv, "Live")
+
+	event := stream.next(t)
+	var payload app.Task
+	if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+		t.Fatalf("failed to decode event data %q: %v", event.Data, err)
+	}
+	epoch, seq, _ := strings.Cut(event.ID, "-")
+	if seq != "1" || event.Type != "task.created" || payload.ID != task.ID || payload.Title != "Live" {
+		t.Fatalf("expected task.created 1 with the task, got %+v", event)
+	}
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s/api/tasks/%d", srv.URL, task.ID), nil)
+	if res, err := srv.Client().Do(req); err == nil {
+		res.Body.Close()
+	}
+	if event := stream.next(t); event.ID != epoch+"-2" || event.Type != "task.deleted" {
+		t.Fatalf("expected task.deleted 2, got %+v", event)
+	}
+	stream.close()
+
+	// Changes made while disconnected are replayed on reconnection.
+	postTask(t, srv, "Missed")
+	stream = openEventStream(t, srv, epoch+"-2")
+	if event := stream.next(t); event.ID != epoch+"-3" || event.Type != "task.created" || !strings.Contains(event.Data, "Missed") {
+		t.Fatalf("expected the missed task.created 3, got %+v", event)
+	}
+
+	// IDs the server did not issue, e.g. before a restart, ask for a reload.
+	for _, lastID := range []string{epoch + "-99", "1-2", "2"} {
+		stream = openEventStream(t, srv, lastID)
+		if event := stream.next(t); event.Type != "reset" {
+			t.Fatalf("expected a reset event for %s, got %+v", lastID, event)
+		}
+	}
+
+	if w := doRequestWithHeader(newTestRouter(), "GET", "/api/events", "", "Last-Event-ID", "latest"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid Last-Event-ID, got %d", w.Code)
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := app.NewHub()
+	ada := app.WithWorkspace(context.Background(), 1)
+	zed := app.WithWorkspace(context.Background(), 2)
+
+	adaSub, _, _ := hub.Subscribe(ada, "")
+	zedSub, _, _ := hub.Subscribe(zed, "")
+	hub.Publish(app.HubEvent{Type: app.TaskCreated, WorkspaceID: 1, Task: &app.Task{ID: 1}})
+
+	first := <-adaSub.Events()
+	if !strings.HasSuffix(first.ID, "-1") || first.Task.ID != 1 {
+		t.Fatalf("expected Ada's event, got %+v", first)
+	}
+	epoch := strings.TrimSuffix(first.ID, "-1")
+	select {
+	case event := <-zedSub.Events():
+		t.Fatalf("expected no event for another workspace, got %+v", event)
+	default:
+	}
+
+	// A subscriber that stops reading is dropped rather than blocking the hub.
+	for i := 0; i < 100; i++ {
+		hub.Publish(app.HubEvent{Type: app.TaskUpdated, WorkspaceID: 1, Task: &app.Task{ID: 1}})
+	}
+	received := 0
+	for range adaSub.Events() {
+		received++
+	}
+	if received == 0 || received == 100 {
+		t.Fatalf("expected the slow subscriber dropped after a buffer of events, got %d", received)
+	}
+	hub.Unsubscribe(adaSub)
+	hub.Unsubscribe(zedSub)
+	if _, ok := <-zedSub.Events(); ok {
+		t.Fatal("expected the subscription closed")
+	}
+
+	// The retained events let a dropped subscriber resume.
+	sub, missed, resumed := hub.Subscribe(ada, epoch+"-50")
+	if !resumed || len(missed) != 51 || missed[0].ID != epoch+"-51" {
+		t.Fatalf("expected events 51 to 101 replayed, got %d (resumed %v)", len(missed), resumed)
+	}
+
+	// Another hub, as after a restart, does not resume the IDs of this one.
+	restarted := app.NewHub()
+	restarted.Publish(app.HubEvent{Type: app.TaskCreated, WorkspaceID: 1, Task: &app.Task{ID: 2}})
+	if _, missed, resumed := restarted.Subscribe(ada, epoch+"-0"); resumed || len(missed) != 0 {
+		t.Fatalf("expected a reset for another hub's ID, got %d (resumed %v)", len(missed), resumed)
+	}
+
+	// Closing the hub ends every subscription, present and future.
+	hub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("expected the subscription closed with the hub")
+	}
+	sub, _, _ = hub.Subscribe(ada, "")
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("expected subscriptions to a closed hub to end")
+	}
+}
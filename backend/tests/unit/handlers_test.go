@@ -1,26 +1,350 @@
 package unit
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"taskboard-backend/app"
 )
 
-func TestGetTasks(t *testing.T) {
+func newTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
+	return app.NewServer(app.Stores{Tasks: app.NewMemoryTaskStore()}, nil).Router()
+}
 
-	r := gin.Default()
-	r.GET("/api/tasks", func(c *gin.Context) {
-		c.JSON(http.StatusOK, []string{})
-	})
+func doRequest(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
 
+// doRequestWithHeader is doRequest with one more header, such as If-Match.
+func doRequestWithHeader(r http.Handler, method, path, body, header, value string) *httptest.ResponseRecorder {
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/api/tasks", nil)
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(header, value)
 	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeTask(t *testing.T, w *httptest.ResponseRecorder) app.Task {
+	t.Helper()
+	var task app.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &task); err != nil {
+		t.Fatalf("failed to decode task: %v (body %s)", err, w.Body.String())
+	}
+	return task
+}
+
+func TestGetTasks(t *testing.T) {
+	r := newTestRouter()
+
+	w := doRequest(r, "GET", "/api/tasks", "")
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Fatalf("expected empty list, got %s", w.Body.String())
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	r := newTestRouter()
+
+	w := doRequest(r, "POST", "/api/tasks", `{"title":"Write tests"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+
+	task := decodeTask(t, w)
+	if task.ID == 0 || task.Title != "Write tests" || task.Completed {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+
+	w = doRequest(r, "GET", "/api/tasks", "")
+	var tasks []app.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+		t.Fatalf("failed to decode tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected created task in list, got %+v", tasks)
+	}
+}
+
+func TestCreateTaskRejectsInvalidInput(t *testing.T) {
+	r := newTestRouter()
+
+	for _, body := range []string{`{}`, `{"title":""}`, `not json`} {
+		w := doRequest(r, "POST", "/api/tasks", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %q: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestUpdateTask(t *testing.T) {
+	r := newTestRouter()
+
+	created := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Toggle me"}`))
+
+	w := doRequest(r, "PATCH", "/api/tasks/1", `{"completed":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	updated := decodeTask(t, w)
+	if updated.ID != created.ID || !updated.Completed || updated.Title != "Toggle me" {
+		t.Fatalf("unexpected task: %+v", updated)
+	}
+}
+
+func TestUpdateTaskNotFound(t *testing.T) {
+	r := newTestRouter()
+
+	if w := doRequest(r, "PUT", "/api/tasks/42", `{"completed":true}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if w := doRequest(r, "PUT", "/api/tasks/abc", `{"completed":true}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestReplaceTask(t *testing.T) {
+	r := newTestRouter()
+	doRequest(r, "POST", "/api/tasks", `{"title":"Ship","description":"Soon","priority":"high","due_at":"2030-01-01T00:00:00Z"}`)
+
+	// Fields missing from a PUT body are reset.
+	w := doRequest(r, "PUT", "/api/tasks/1", `{"title":"Ship it","completed":true}`)
+	task := decodeTask(t, w)
+	if w.Code != http.StatusOK || task.Title != "Ship it" || !task.Completed ||
+		task.Description != "" || task.Priority != app.PriorityMedium || task.DueAt != nil {
+		t.Fatalf("expected the task replaced, got %d %+v", w.Code, task)
+	}
+
+	w = doRequest(r, "PUT", "/api/tasks/1", `{"title":"","description":"`+strings.Repeat("x", 10001)+`","priority":"critical"}`)
+	var body app.Problem
+	decodeJSON(t, w, &body)
+	want := []app.FieldError{
+		{Field: "title", Message: "is required"},
+		{Field: "description", Message: "must be at most 10000 characters"},
+		{Field: "priority", Message: "must be one of low, medium, high, urgent"},
+	}
+	if w.Code != http.StatusBadRequest || !slices.Equal(body.Errors, want) {
+		t.Fatalf("expected 400 with field errors, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(r, "PUT", "/api/tasks/1", `{"title":"`+strings.Repeat("x", 201)+`"}`)
+	decodeJSON(t, w, &body)
+	if w.Code != http.StatusBadRequest || len(body.Errors) != 1 || body.Errors[0].Message != "must be at most 200 characters" {
+		t.Fatalf("expected 400 for a long title, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	r := newTestRouter()
+	doRequest(r, "POST", "/api/tasks", `{"title":"Ship","description":"Soon","priority":"high","due_at":"2030-01-01T00:00:00Z"}`)
+
+	// Only the listed fields change; null resets a field.
+	w := doRequestWithHeader(r, "PATCH", "/api/tasks/1", `{"completed":true,"due_at":null}`, "Content-Type", "application/merge-patch+json")
+	task := decodeTask(t, w)
+	if w.Code != http.StatusOK || !task.Completed || task.DueAt != nil ||
+		task.Title != "Ship" || task.Description != "Soon" || task.Priority != app.PriorityHigh {
+		t.Fatalf("expected the task patched, got %d %+v", w.Code, task)
+	}
+
+	var body app.Problem
+	for patch, want := range map[string]app.FieldError{
+		`{"title":null}`:      {Field: "title", Message: "is required"},
+		`{"title":42}`:        {Field: "title", Message: "must be a string"},
+		`{"priority":"soon"}`: {Field: "priority", Message: "must be one of low, medium, high, urgent"},
+	} {
+		w := doRequest(r, "PATCH", "/api/tasks/1", patch)
+		decodeJSON(t, w, &body)
+		if w.Code != http.StatusBadRequest || len(body.Errors) != 1 || body.Errors[0] != want {
+			t.Errorf("%s: expected 400 with %+v, got %d %s", patch, want, w.Code, w.Body.String())
+		}
+	}
+
+	if w := doRequestWithHeader(r, "PATCH", "/api/tasks/1", `{"completed":false}`, "Content-Type", "text/plain"); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d", w.Code)
+	}
+	if w := doRequest(r, "PATCH", "/api/tasks/1", `{"completed":`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed JSON, got %d", w.Code)
+	}
+	if w := doRequest(r, "PATCH", "/api/tasks/42", `{"completed":false}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestDeleteTask(t *testing.T) {
+	r := newTestRouter()
+
+	doRequest(r, "POST", "/api/tasks", `{"title":"Remove me"}`)
+
+	if w := doRequest(r, "DELETE", "/api/tasks/1", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	w := doRequest(r, "GET", "/api/tasks", "")
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Fatalf("expected empty list after delete, got %s", w.Body.String())
+	}
+}
+
+func TestGetTasksPagination(t *testing.T) {
+	r := newTestRouter()
+
+	for i := 0; i < 5; i++ {
+		doRequest(r, "POST", "/api/tasks", `{"title":"Task"}`)
+	}
+
+	var ids []uint
+	path := "/api/tasks?limit=2"
+	for pages := 0; path != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+
+		w := doRequest(r, "GET", path, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var tasks []app.Task
+		if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+			t.Fatalf("failed to decode tasks: %v", err)
+		}
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+
+		path = ""
+		if link := w.Header().Get("Link"); link != "" {
+			path = strings.TrimPrefix(strings.SplitN(link, ">", 2)[0], "<")
+		}
+	}
+
+	want := []uint{5, 4, 3, 2, 1}
+	if len(ids) != len(want) {
+		t.Fatalf("expected ids %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("expected ids %v, got %v", want, ids)
+		}
+	}
+}
+
+func TestGetTasksRejectsInvalidQuery(t *testing.T) {
+	r := newTestRouter()
+
+	for _, query := range []string{
+		"limit=0",
+		"limit=1000",
+		"completed=maybe",
+		"created_after=yesterday",
+		"sort=priority",
+		"cursor=not-a-cursor",
+		"assignee=me",
+		"assignee=someone",
+	} {
+		if w := doRequest(r, "GET", "/api/tasks?"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	r := newTestRouter()
+
+	doRequest(r, "POST", "/api/tasks", `{"title":"Plan sprint"}`)
+	doRequest(r, "POST", "/api/tasks", `{"title":"Review PR"}`)
+
+	w := doRequest(r, "GET", "/api/tasks/search?q=sprint", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var results []app.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("failed to decode results: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Plan sprint" || results[0].Highlight != "Plan <mark>sprint</mark>" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	if w := doRequest(r, "GET", "/api/tasks/search", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without q, got %d", w.Code)
+	}
+}
+
+func TestCreateTaskWithDetails(t *testing.T) {
+	r := newTestRouter()
+
+	w := doRequest(r, "POST", "/api/tasks",
+		`{"title":"Ship release","description":"Tag **v1.2**","priority":"urgent","due_at":"2020-01-01T09:00:00Z"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	task := decodeTask(t, w)
+	if task.Description != "Tag **v1.2**" || task.Priority != app.PriorityUrgent || task.DueAt == nil {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+
+	defaulted := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Plain"}`))
+	if defaulted.Priority != app.PriorityMedium {
+		t.Fatalf("expected default priority medium, got %q", defaulted.Priority)
+	}
+
+	w = doRequest(r, "GET", "/api/tasks?overdue=true", "")
+	var tasks []app.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+		t.Fatalf("failed to decode tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected the overdue task, got %+v", tasks)
+	}
+
+	updated := decodeTask(t, doRequest(r, "PATCH", "/api/tasks/1", `{"priority":"low","description":"Done soon"}`))
+	if updated.Priority != app.PriorityLow || updated.Description != "Done soon" || updated.Title != "Ship release" {
+		t.Fatalf("unexpected task after update: %+v", updated)
+	}
+}
+
+func TestTaskDetailsValidation(t *testing.T) {
+	r := newTestRouter()
+
+	long := strings.Repeat("x", 10001)
+	for _, body := range []string{
+		`{"title":"x","priority":"critical"}`,
+		`{"title":"x","description":"` + long + `"}`,
+		`{"title":"x","due_at":"tomorrow"}`,
+	} {
+		if w := doRequest(r, "POST", "/api/tasks", body); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 creating %.40s, got %d", body, w.Code)
+		}
+	}
+
+	doRequest(r, "POST", "/api/tasks", `{"title":"x"}`)
+	if w := doRequest(r, "PATCH", "/api/tasks/1", `{"priority":"critical"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 updating priority, got %d", w.Code)
+	}
+	if w := doRequest(r, "GET", "/api/tasks?priority=critical", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 filtering on priority, got %d", w.Code)
+	}
 }
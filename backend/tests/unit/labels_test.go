@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"taskboard-backend/app"
+)
+
+func createLabel(t *testing.T, r http.Handler, body string) app.Label {
+	t.Helper()
+	w := doRequest(r, "POST", "/api/labels", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var label app.Label
+	decodeJSON(t, w, &label)
+	return label
+}
+
+func TestLabelLifecycle(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	bug := createLabel(t, r, `{"name":"bug","color":"#ef4444"}`)
+	chore := createLabel(t, r, `{"name":"chore"}`)
+	if chore.Color == "" {
+		t.Fatalf("expected a default color, got %+v", chore)
+	}
+
+	if w := doRequest(r, "POST", "/api/labels", `{"name":"bug"}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate name, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/api/labels", `{"name":"x","color":"red"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid color, got %d", w.Code)
+	}
+
+	w := doRequest(r, "PUT", fmt.Sprintf("/api/labels/%d", chore.ID), `{"name":"maintenance"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var labels []app.Label
+	decodeJSON(t, doRequest(r, "GET", "/api/labels", ""), &labels)
+	if len(labels) != 2 || labels[0].Name != "bug" || labels[1].Name != "maintenance" {
+		t.Fatalf("expected labels ordered by name, got %+v", labels)
+	}
+
+	doRequest(r, "POST", "/api/tasks", `{"title":"Crash on save"}`)
+	w = doRequest(r, "POST", "/api/tasks/1/labels", fmt.Sprintf(`{"label_ids":[%d,%d]}`, bug.ID, chore.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if task := decodeTask(t, w); len(task.Labels) != 2 {
+		t.Fatalf("expected 2 labels, got %+v", task.Labels)
+	}
+
+	if w := doRequest(r, "POST", "/api/tasks/1/labels", `{"label_ids":[99]}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown label, got %d", w.Code)
+	}
+
+	w = doRequest(r, "DELETE", fmt.Sprintf("/api/tasks/1/labels/%d", chore.ID), "")
+	if task := decodeTask(t, w); len(task.Labels) != 1 || task.Labels[0].Name != "bug" {
+		t.Fatalf("expected only the bug label, got %+v", task.Labels)
+	}
+
+	if w := doRequest(r, "DELETE", fmt.Sprintf("/api/labels/%d", bug.ID), ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	var tasks []app.Task
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks", ""), &tasks)
+	if len(tasks) != 1 || len(tasks[0].Labels) != 0 {
+		t.Fatalf("expected deleted label to be detached, got %+v", tasks)
+	}
+
+	if w := doRequest(r, "DELETE", "/api/tasks/1", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 deleting a labeled task, got %d", w.Code)
+	}
+}
+
+func TestLabelFilter(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	bug := createLabel(t, r, `{"name":"bug"}`)
+	customer := createLabel(t, r, `{"name":"customer"}`)
+	createLabel(t, r, `{"name":"feature"}`)
+
+	for i, ids := range [][]uint{{bug.ID}, {bug.ID, customer.ID}, {customer.ID}, nil} {
+		doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"task %d"}`, i+1))
+		if ids != nil {
+			body, _ := json.Marshal(app.AttachLabelsInput{LabelIDs: ids})
+			doRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/labels", i+1), string(body))
+		}
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{"label=bug", 2},
+		{"label=bug,customer", 3},
+		{"label=bug,customer&label_match=all", 1},
+		{"label=feature", 0},
+		{"label=bug,feature&label_match=all", 0},
+	} {
+		var tasks []app.Task
+		decodeJSON(t, doRequest(r, "GET", "/api/tasks?"+tc.query, ""), &tasks)
+		if len(tasks) != tc.want {
+			t.Errorf("%s: expected %d tasks, got %d", tc.query, tc.want, len(tasks))
+		}
+	}
+
+	if w := doRequest(r, "GET", "/api/tasks?label=bug&label_match=some", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid label_match, got %d", w.Code)
+	}
+}
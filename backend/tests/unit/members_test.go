@@ -0,0 +1,266 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"taskboard-backend/app"
+)
+
+func TestBoardRoles(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, owner := registerUser(t, r, "Ada")
+	viewer, viewerTokens := addTeammate(t, r, owner.AccessToken, "Bo")
+	editor, editorTokens := addTeammate(t, r, owner.AccessToken, "Cy")
+	_, outsider := addTeammate(t, r, owner.AccessToken, "Dee")
+
+	w := doAuthRequest(r, "POST", "/api/boards", `{"name":"Launch"}`, owner.AccessToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	var board app.Board
+	decodeJSON(t, w, &board)
+	todo := board.Columns[0].ID
+	members := fmt.Sprintf("/api/boards/%d/members", board.ID)
+
+	var listed []app.BoardMember
+	decodeJSON(t, doAuthRequest(r, "GET", members, "", viewerTokens.AccessToken), &listed)
+	if len(listed) != 1 || listed[0].Role != app.RoleOwner || listed[0].User == nil || listed[0].User.Name != "Ada" {
+		t.Fatalf("expected the creator as owner, got %+v", listed)
+	}
+
+	for _, m := range []struct {
+		user app.User
+		role app.Role
+	}{{viewer, app.RoleViewer}, {editor, app.RoleEditor}} {
+		body := fmt.Sprintf(`{"user_id":%d,"role":%q}`, m.user.ID, m.role)
+		if w := doAuthRequest(r, "POST", members, body, owner.AccessToken); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 adding %s, got %d: %s", m.role, w.Code, w.Body.String())
+		}
+	}
+	if w := doAuthRequest(r, "POST", members, fmt.Sprintf(`{"user_id":%d,"role":"owner"}`, viewer.ID), owner.AccessToken); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 adding a member twice, got %d", w.Code)
+	}
+
+	w = doAuthRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Ship it","column_id":%d}`, todo), owner.AccessToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	task := decodeTask(t, w)
+	if task.ColumnID == nil || *task.ColumnID != todo {
+		t.Fatalf("expected the task in column %d, got %+v", todo, task.ColumnID)
+	}
+	taskPath := fmt.Sprintf("/api/tasks/%d", task.ID)
+
+	// Viewers and outsiders can read but not change the board's tasks.
+	for name, token := range map[string]string{"viewer": viewerTokens.AccessToken, "outsider": outsider.AccessToken} {
+		if w := doAuthRequest(r, "GET", "/api/tasks", "", token); w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200 listing tasks, got %d", name, w.Code)
+		}
+		if w := doAuthRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Nope","column_id":%d}`, todo), token); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 creating a task, got %d", name, w.Code)
+		}
+		if w := doAuthRequest(r, "PATCH", taskPath, `{"completed":true}`, token); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 updating a task, got %d", name, w.Code)
+		}
+		if w := doAuthRequest(r, "DELETE", taskPath, "", token); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 deleting a task, got %d", name, w.Code)
+		}
+		bulk := fmt.Sprintf(`{"operations":[{"op":"complete","id":%d}]}`, task.ID)
+		if w := doAuthRequest(r, "POST", "/api/tasks/bulk", bulk, token); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 changing a task in bulk, got %d", name, w.Code)
+		}
+	}
+
+	// Editors can change tasks and columns but not the board or its members.
+	if w := doAuthRequest(r, "PATCH", taskPath, `{"title":"Ship it today"}`, editorTokens.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for an editor, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", fmt.Sprintf("/api/boards/%d/columns", board.ID), `{"name":"Review"}`, editorTokens.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 creating a column as editor, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "PUT", fmt.Sprintf("/api/boards/%d", board.ID), `{"name":"Mine"}`, editorTokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 renaming the board as editor, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "DELETE", fmt.Sprintf("%s/%d", members, viewer.ID), "", editorTokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 managing members as editor, got %d", w.Code)
+	}
+
+	// Tasks cannot be moved onto a board the user may not edit.
+	w = doAuthRequest(r, "POST", "/api/boards", `{"name":"Private"}`, outsider.AccessToken)
+	var private app.Board
+	decodeJSON(t, w, &private)
+	if w := doAuthRequest(r, "POST", taskPath+"/move", fmt.Sprintf(`{"column_id":%d}`, private.Columns[0].ID), editorTokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 moving to a foreign board, got %d", w.Code)
+	}
+
+	// Promoting a viewer takes effect right away.
+	if w := doAuthRequest(r, "PUT", fmt.Sprintf("%s/%d", members, viewer.ID), `{"role":"editor"}`, owner.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "PATCH", taskPath, `{"completed":true}`, viewerTokens.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after promotion, got %d", w.Code)
+	}
+}
+
+func TestBoardKeepsOwner(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	ada, owner := registerUser(t, r, "Ada")
+	bo, _ := addTeammate(t, r, owner.AccessToken, "Bo")
+
+	var board app.Board
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/boards", `{"name":"Launch"}`, owner.AccessToken), &board)
+	members := fmt.Sprintf("/api/boards/%d/members", board.ID)
+	adaPath := fmt.Sprintf("%s/%d", members, ada.ID)
+
+	if w := doAuthRequest(r, "PUT", adaPath, `{"role":"editor"}`, owner.AccessToken); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 demoting the last owner, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "DELETE", adaPath, "", owner.AccessToken); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 removing the last owner, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", members, `{"user_id":99,"role":"viewer"}`, owner.AccessToken); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown user, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", members, `{"user_id":1,"role":"admin"}`, owner.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown role, got %d", w.Code)
+	}
+
+	// Once another owner exists the first may leave.
+	if w := doAuthRequest(r, "POST", members, fmt.Sprintf(`{"user_id":%d,"role":"owner"}`, bo.ID), owner.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "DELETE", adaPath, "", owner.AccessToken); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "PUT", fmt.Sprintf("/api/boards/%d", board.ID), `{"name":"Mine"}`, owner.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 once the user left, got %d", w.Code)
+	}
+}
+
+func TestOpenBoardNeedsOwner(t *testing.T) {
+	r := newSQLiteRouter(t)
+	user := createUser(t, r, "Ada")
+
+	// Boards created without authentication have no members.
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Legacy"}`), &board)
+	members := fmt.Sprintf("/api/boards/%d/members", board.ID)
+
+	if w := doRequest(r, "POST", members, fmt.Sprintf(`{"user_id":%d,"role":"viewer"}`, user.ID)); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when the first member is not an owner, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", members, fmt.Sprintf(`{"user_id":%d,"role":"owner"}`, user.ID)); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+}
+
+func TestWorkspaceRoles(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, owner := registerUser(t, r, "Ada")
+	_, editor := addTeammate(t, r, owner.AccessToken, "Cy")
+
+	body := `{"name":"Bo","email":"bo@example.com","password":"correct horse","role":"viewer"}`
+	if w := doAuthRequest(r, "POST", "/api/users", body, owner.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var viewer app.TokenPair
+	decodeJSON(t, doRequest(r, "POST", "/api/auth/login", `{"email":"bo@example.com","password":"correct horse"}`), &viewer)
+
+	// Tasks off boards follow the workspace role.
+	task := decodeTask(t, doAuthRequest(r, "POST", "/api/tasks", `{"title":"Loose"}`, editor.AccessToken))
+	taskPath := fmt.Sprintf("/api/tasks/%d", task.ID)
+	for _, req := range []struct{ method, path, body string }{
+		{"POST", "/api/tasks", `{"title":"Nope"}`},
+		{"PATCH", taskPath, `{"completed":true}`},
+		{"DELETE", taskPath, ""},
+	} {
+		if w := doAuthRequest(r, req.method, req.path, req.body, viewer.AccessToken); w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403 for a viewer's %s %s, got %d", req.method, req.path, w.Code)
+		}
+	}
+	w := doAuthRequest(r, "POST", "/api/tasks/bulk", `{"operations":[{"op":"create","task":{"title":"Nope"}}]}`, viewer.AccessToken)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a viewer's bulk create, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "GET", taskPath, "", viewer.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected viewers to read tasks, got %d", w.Code)
+	}
+	// Nor can viewers become the owner of a board of their own.
+	if w := doAuthRequest(r, "POST", "/api/boards", `{"name":"Mine"}`, viewer.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a viewer creating a board, got %d", w.Code)
+	}
+
+	// Labels are shared by the workspace's editors; only owners rename it.
+	if w := doAuthRequest(r, "POST", "/api/labels", `{"name":"urgent"}`, viewer.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a viewer creating a label, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", "/api/labels", `{"name":"urgent"}`, editor.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for an editor creating a label, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "PUT", "/api/workspace", `{"name":"Taken"}`, editor.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an editor renaming the workspace, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "PUT", "/api/workspace", `{"name":"Ours"}`, owner.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the owner renaming the workspace, got %d", w.Code)
+	}
+
+	// Users may only grant the roles they hold.
+	body = `{"name":"Eve","email":"eve@example.com","role":"owner"}`
+	if w := doAuthRequest(r, "POST", "/api/users", body, editor.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an editor creating an owner, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", "/api/users", body, owner.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for an owner creating an owner, got %d", w.Code)
+	}
+}
+
+func TestOpenBoardFollowsWorkspaceRole(t *testing.T) {
+	auth, err := app.NewAuthenticator(testAuthConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newSQLiteDB(t)
+	r := app.NewServer(app.NewGormStores(db), auth).Router()
+	ada, owner := registerUser(t, r, "Ada")
+	bo, editor := addTeammate(t, r, owner.AccessToken, "Bo")
+
+	// A board from before roles existed has no members.
+	var board app.Board
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/boards", `{"name":"Legacy"}`, owner.AccessToken), &board)
+	if err := db.Where("board_id = ?", board.ID).Delete(&app.BoardMember{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	members := fmt.Sprintf("/api/boards/%d/members", board.ID)
+
+	if w := doAuthRequest(r, "POST", members, fmt.Sprintf(`{"user_id":%d,"role":"owner"}`, bo.ID), editor.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an editor claiming an open board, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", fmt.Sprintf("/api/boards/%d/columns", board.ID), `{"name":"Review"}`, editor.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected editors to edit an open board, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", members, fmt.Sprintf(`{"user_id":%d,"role":"owner"}`, ada.ID), owner.AccessToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected the workspace owner to claim the board, got %d", w.Code)
+	}
+}
+
+func TestDeletedUserHasNoRole(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, owner := registerUser(t, r, "Ada")
+	bo, editor := addTeammate(t, r, owner.AccessToken, "Bo")
+
+	if w := doAuthRequest(r, "DELETE", fmt.Sprintf("/api/users/%d", bo.ID), "", editor.AccessToken); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	// The token outlives its user, but grants nothing.
+	for _, req := range []struct{ method, path, body string }{
+		{"POST", "/api/labels", `{"name":"urgent"}`},
+		{"POST", "/api/tasks", `{"title":"Orphan"}`},
+		{"POST", "/api/tasks/bulk", `{"operations":[{"op":"create","task":{"title":"Orphan"}}]}`},
+	} {
+		if w := doAuthRequest(r, req.method, req.path, req.body, editor.AccessToken); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected 403 for a deleted user, got %d %s", req.method, req.path, w.Code, w.Body.String())
+		}
+	}
+}
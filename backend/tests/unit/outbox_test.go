@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"taskboard-backend/app"
+)
+
+// startRelay runs the outbox relay of server until the test ends.
+func startRelay(t *testing.T, server *app.Server) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.RunRelay(ctx, app.OutboxConfig{PollInterval: 10 * time.Millisecond, Retention: time.Hour})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// recordingSink records the events it handles, failing the first ones.
+type recordingSink struct {
+	mu       sync.Mutex
+	events   []app.HubEvent
+	failures int
+}
+
+func (s *recordingSink) Handle(_ context.Context, event app.HubEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	stores := app.NewGormStores(newSQLiteDB(t))
+	// No relay runs: the events wait in the outbox.
+	r := app.NewServer(stores, nil).Router()
+
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Draft"}`))
+	path := fmt.Sprintf("/api/tasks/%d", task.ID)
+	doRequest(r, "PATCH", path, `{"title":"Final"}`)
+	doRequest(r, "DELETE", path, "")
+
+	// A rolled back batch writes nothing to the outbox.
+	w := doRequest(r, "POST", "/api/tasks/bulk", `{"operations":[{"op":"create","task":{"title":"Ghost"}},{"op":"delete","id":0}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected the batch to fail, got %d", w.Code)
+	}
+
+	sink := &recordingSink{failures: 1}
+	relay := app.NewOutboxRelay(stores.Outbox, stores.Tx, sink)
+	ctx := context.Background()
+
+	// A failing sink holds the event back for a retry.
+	if n, err := relay.RelayPending(ctx); err == nil || n != 0 {
+		t.Fatalf("expected the first attempt to fail, got %d (%v)", n, err)
+	}
+	if n, err := relay.RelayPending(ctx); err != nil || n != 3 {
+		t.Fatalf("expected 3 relayed events, got %d (%v)", n, err)
+	}
+	want := []string{app.TaskCreated, app.TaskUpdated, app.TaskDeleted}
+	if len(sink.events) != len(want) {
+		t.Fatalf("expected events %v, got %+v", want, sink.events)
+	}
+	for i, event := range sink.events {
+		if event.Type != want[i] || event.Task == nil || event.Task.ID != task.ID {
+			t.Fatalf("expected %s of task %d at %d, got %+v", want[i], task.ID, i, event)
+		}
+	}
+	if sink.events[1].Task.Title != "Final" {
+		t.Fatalf("expected the updated task, got %+v", sink.events[1].Task)
+	}
+
+	// Processed events are not relayed again.
+	if n, err := relay.RelayPending(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to relay, got %d (%v)", n, err)
+	}
+}
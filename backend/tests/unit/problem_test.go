@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"taskboard-backend/app"
+)
+
+// brokenTaskStore fails every lookup as if the database were down.
+type brokenTaskStore struct {
+	app.TaskStore
+}
+
+func (brokenTaskStore) Get(context.Context, uint) (*app.Task, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestProblemResponses(t *testing.T) {
+	r := newTestRouter()
+
+	w := doRequest(r, "PATCH", "/api/tasks/42", `{"completed":true}`)
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", ct)
+	}
+	var problem app.Problem
+	decodeJSON(t, w, &problem)
+	want := app.Problem{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "task not found", Instance: "/api/tasks/42"}
+	if w.Code != http.StatusNotFound || problem.Type != want.Type || problem.Title != want.Title ||
+		problem.Status != want.Status || problem.Detail != want.Detail || problem.Instance != want.Instance {
+		t.Fatalf("expected %+v, got %d %+v", want, w.Code, problem)
+	}
+
+	w = doRequest(r, "POST", "/api/tasks", `{"title":`)
+	problem = app.Problem{}
+	decodeJSON(t, w, &problem)
+	if w.Code != http.StatusBadRequest || problem.Detail != "invalid input: unexpected EOF" || problem.Errors != nil {
+		t.Fatalf("expected the binding error as detail, got %d %+v", w.Code, problem)
+	}
+
+	w = doRequest(r, "GET", "/api/nothing", "")
+	problem = app.Problem{}
+	decodeJSON(t, w, &problem)
+	if w.Code != http.StatusNotFound || problem.Detail != "no route matches GET /api/nothing" {
+		t.Fatalf("expected a problem for unknown routes, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProblemTraceID(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	w := doRequest(newTestRouter(), "PUT", "/api/tasks/42", `{"title":"x"}`)
+	var problem app.Problem
+	decodeJSON(t, w, &problem)
+	if len(problem.TraceID) != 32 {
+		t.Fatalf("expected a trace ID, got %+v", problem)
+	}
+}
+
+func TestProblemInternalError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := app.NewServer(app.Stores{Tasks: brokenTaskStore{app.NewMemoryTaskStore()}}, nil).Router()
+
+	// A failing database is not reported as a missing task.
+	for _, method := range []string{"GET", "PUT", "PATCH"} {
+		w := doRequest(r, method, "/api/tasks/1", `{"title":"x"}`)
+		var problem app.Problem
+		decodeJSON(t, w, &problem)
+		if w.Code != http.StatusInternalServerError || problem.Status != http.StatusInternalServerError {
+			t.Errorf("%s: expected 500, got %d %+v", method, w.Code, problem)
+		}
+	}
+}
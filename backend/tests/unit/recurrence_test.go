@@ -0,0 +1,173 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"taskboard-backend/app"
+)
+
+func TestRRule(t *testing.T) {
+	// Friday 16 October 2026, 09:30 UTC.
+	anchor := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 9, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		rule  string
+		after time.Time
+		want  time.Time
+	}{
+		{"FREQ=DAILY", anchor, day(10, 17)},
+		{"FREQ=DAILY;INTERVAL=3", day(10, 20), day(10, 22)},
+		{"FREQ=WEEKLY", anchor, day(10, 23)},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO", anchor, day(10, 19)},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", day(10, 19), day(10, 23)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", anchor, day(10, 26)},
+		{"FREQ=MONTHLY", anchor, day(11, 16)},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", anchor, day(10, 31)},
+		{"FREQ=MONTHLY;BYDAY=-1FR", anchor, day(10, 30)},
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", anchor, day(11, 26)},
+		{"FREQ=YEARLY", anchor, time.Date(2027, 10, 16, 9, 30, 0, 0, time.UTC)},
+		// The due date has long passed: the next occurrence is in the future.
+		{"FREQ=WEEKLY", day(11, 30), day(12, 4)},
+	}
+	for _, tt := range tests {
+		rule, err := app.ParseRRule(tt.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.rule, err)
+		}
+		if got, ok := rule.Next(anchor, tt.after); !ok || !got.Equal(tt.want) {
+			t.Errorf("%s after %s: expected %s, got %s (%v)", tt.rule, tt.after, tt.want, got, ok)
+		}
+	}
+
+	rule, _ := app.ParseRRule("FREQ=DAILY;UNTIL=20261017")
+	if _, ok := rule.Next(anchor, day(10, 17)); ok {
+		t.Error("expected no occurrence past UNTIL")
+	}
+	if rule, _ := app.ParseRRule("freq=weekly;byday=mo,fr;interval=2"); rule.String() != "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR" {
+		t.Errorf("expected the canonical rule, got %s", rule)
+	}
+
+	for _, invalid := range []string{"", "BYDAY=MO", "FREQ=HOURLY", "FREQ=DAILY;COUNT=2;UNTIL=20261231", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;BYSETPOS=1", "FREQ=DAILY;FREQ=WEEKLY"} {
+		if _, err := app.ParseRRule(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestRecurringTasks(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	w := doRequest(r, "POST", "/api/tasks", `{"title":"Chore","recurrence":"FREQ=SOMETIMES"}`)
+	var problem app.Problem
+	decodeJSON(t, w, &problem)
+	if w.Code != http.StatusBadRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "recurrence" {
+		t.Fatalf("expected a recurrence field error, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Completing a recurring task creates its next occurrence, which takes
+	// the rule over.
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Home"}`), &board)
+	chore := createLabel(t, r, `{"name":"chore"}`)
+	due := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Bins","priority":"high","due_at":%q,"recurrence":"FREQ=WEEKLY;COUNT=2","column_id":%d}`, due.Format(time.RFC3339), board.Columns[1].ID)))
+	doRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/labels", task.ID), fmt.Sprintf(`{"label_ids":[%d]}`, chore.ID))
+	w = doRequest(r, "PATCH", fmt.Sprintf("/api/tasks/%d", task.ID), `{"completed":true}`)
+	if done := decodeTask(t, w); !done.Completed || done.Recurrence != "" {
+		t.Fatalf("expected the completed task to give its rule up, got %+v", done)
+	}
+
+	var tasks []app.Task
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks?recurring=true", ""), &tasks)
+	if len(tasks) != 1 {
+		t.Fatalf("expected one recurring task, got %+v", tasks)
+	}
+	next := tasks[0]
+	if next.Title != "Bins" || next.Priority != app.PriorityHigh || next.Completed || next.Recurrence != "FREQ=WEEKLY;COUNT=1" ||
+		next.RecurrenceOf == nil || *next.RecurrenceOf != task.ID || next.DueAt == nil || !next.DueAt.Equal(due.AddDate(0, 0, 7)) {
+		t.Fatalf("expected the occurrence a week later, got %+v", next)
+	}
+	if len(next.Labels) != 1 || next.Labels[0].ID != chore.ID || next.ColumnID == nil || *next.ColumnID != board.Columns[0].ID {
+		t.Fatalf("expected the labels copied and the first column, got %+v", next)
+	}
+
+	// The last occurrence of a counted rule has no successor.
+	doRequest(r, "PATCH", fmt.Sprintf("/api/tasks/%d", next.ID), `{"completed":true}`)
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks", ""), &tasks)
+	if len(tasks) != 2 {
+		t.Fatalf("expected no further occurrence, got %+v", tasks)
+	}
+}
+
+func TestScheduleRecurrences(t *testing.T) {
+	stores := app.NewGormStores(newSQLiteDB(t))
+	// Two replicas share the database.
+	servers := []*app.Server{app.NewServer(stores, nil), app.NewServer(stores, nil)}
+	r := servers[0].Router()
+
+	due := time.Now().Add(-30 * time.Hour).UTC().Truncate(time.Second)
+	task := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Water plants","due_at":%q,"recurrence":"FREQ=DAILY"}`, due.Format(time.RFC3339))))
+	decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Someday","recurrence":"FREQ=DAILY"}`))
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	created := make([]int, len(servers))
+	for i, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if created[i], err = server.ScheduleRecurrences(ctx); err != nil {
+				t.Errorf("failed to schedule: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if created[0]+created[1] != 1 {
+		t.Fatalf("expected one occurrence in all, got %v", created)
+	}
+	if n, err := servers[1].ScheduleRecurrences(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to schedule, got %d (%v)", n, err)
+	}
+
+	// The overdue task stays open; its occurrence is due in the future.
+	var tasks []app.Task
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks?recurring=true&sort=created_at", ""), &tasks)
+	if len(tasks) != 2 || tasks[1].RecurrenceOf == nil || *tasks[1].RecurrenceOf != task.ID {
+		t.Fatalf("expected the undated task and the new occurrence, got %+v", tasks)
+	}
+	if next := tasks[1]; !next.DueAt.Equal(due.AddDate(0, 0, 2)) {
+		t.Fatalf("expected the occurrence due in two days' time, got %s", next.DueAt)
+	}
+	if old := decodeTask(t, doRequest(r, "GET", fmt.Sprintf("/api/tasks/%d", task.ID), "")); old.Completed || old.Recurrence != "" {
+		t.Fatalf("expected the overdue task to stay open without its rule, got %+v", old)
+	}
+}
+
+func TestScheduleRecurrencesSkipsFailures(t *testing.T) {
+	db := newSQLiteDB(t)
+	server := app.NewServer(app.NewGormStores(db), nil)
+	r := server.Router()
+
+	done := time.Now().Add(-time.Hour).UTC()
+	broken := decodeTask(t, doRequest(r, "POST", "/api/tasks", `{"title":"Broken","recurrence":"FREQ=DAILY"}`))
+	decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Water plants","due_at":%q,"recurrence":"FREQ=DAILY"}`, done.Format(time.RFC3339))))
+	// A rule stored before validation existed fails to parse.
+	if err := db.Model(&app.Task{}).Where("id = ?", broken.ID).Updates(map[string]any{"recurrence": "FREQ=HOURLY", "completed": true}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := server.ScheduleRecurrences(context.Background())
+	if created != 1 || err == nil || !strings.Contains(err.Error(), fmt.Sprintf("task %d", broken.ID)) {
+		t.Fatalf("expected one occurrence and the broken task's error, got %d (%v)", created, err)
+	}
+}
@@ -0,0 +1,317 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"taskboard-backend/app"
+)
+
+// newSQLiteDB opens a private in-memory SQLite database with all models migrated.
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := app.OpenDB(app.DBConfig{Driver: app.DriverSQLite, Path: ":memory:"}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// forEachStore runs fn against every TaskStore implementation.
+func forEachStore(t *testing.T, fn func(t *testing.T, store app.TaskStore)) {
+	t.Run("memory", func(t *testing.T) {
+		fn(t, app.NewMemoryTaskStore())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		fn(t, app.NewGormTaskStore(newSQLiteDB(t)))
+	})
+}
+
+func TestStoreCRUD(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		task := app.Task{Title: "First"}
+		if err := store.Create(ctx, &task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if task.ID == 0 || task.CreatedAt.IsZero() {
+			t.Fatalf("expected ID and timestamps to be set, got %+v", task)
+		}
+
+		got, err := store.Get(ctx, task.ID)
+		if err != nil || got.Title != "First" {
+			t.Fatalf("get: %+v, %v", got, err)
+		}
+
+		got.Completed = true
+		if err := store.Update(ctx, got); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		completed := true
+		count, err := store.Count(ctx, app.TaskFilter{Completed: &completed})
+		if err != nil || count != 1 {
+			t.Fatalf("expected 1 completed task, got %d (%v)", count, err)
+		}
+
+		if err := store.Delete(ctx, task.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := store.Get(ctx, task.ID); !errors.Is(err, app.ErrTaskNotFound) {
+			t.Fatalf("expected ErrTaskNotFound, got %v", err)
+		}
+	})
+}
+
+func TestStoreListNewestFirst(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		for _, title := range []string{"one", "two", "three"} {
+			if err := store.Create(ctx, &app.Task{Title: title}); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+
+		tasks, err := store.List(ctx, app.TaskFilter{}, app.ListOptions{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(tasks) != 3 || tasks[0].Title != "three" || tasks[2].Title != "one" {
+			t.Fatalf("unexpected order: %+v", tasks)
+		}
+
+		if err := store.DeleteAll(ctx); err != nil {
+			t.Fatalf("delete all: %v", err)
+		}
+		if count, _ := store.Count(ctx, app.TaskFilter{}); count != 0 {
+			t.Fatalf("expected no tasks after DeleteAll, got %d", count)
+		}
+	})
+}
+
+func TestStoreListFilterSortAndPage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		for _, title := range []string{"Buy milk", "buy bread", "Call mom", "Fix 100% bug"} {
+			task := app.Task{Title: title, Completed: title == "Call mom"}
+			if err := store.Create(ctx, &task); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+
+		tasks, err := store.List(ctx, app.TaskFilter{Query: "BUY"}, app.ListOptions{})
+		if err != nil || len(tasks) != 2 {
+			t.Fatalf("expected 2 tasks matching q, got %d (%v)", len(tasks), err)
+		}
+
+		tasks, _ = store.List(ctx, app.TaskFilter{Query: "%"}, app.ListOptions{})
+		if len(tasks) != 1 || tasks[0].Title != "Fix 100% bug" {
+			t.Fatalf("expected LIKE wildcards to be matched literally, got %+v", tasks)
+		}
+
+		completed := false
+		count, _ := store.Count(ctx, app.TaskFilter{Completed: &completed})
+		if count != 3 {
+			t.Fatalf("expected 3 open tasks, got %d", count)
+		}
+
+		byTitle := app.ListOptions{Sort: app.TaskSort{Field: app.SortTitle}, Limit: 2}
+		first, err := store.List(ctx, app.TaskFilter{}, byTitle)
+		if err != nil || len(first) != 2 {
+			t.Fatalf("first page: %+v, %v", first, err)
+		}
+
+		last := first[len(first)-1]
+		byTitle.After = &app.TaskCursor{ID: last.ID, Title: last.Title}
+		second, err := store.List(ctx, app.TaskFilter{}, byTitle)
+		if err != nil || len(second) != 2 {
+			t.Fatalf("second page: %+v, %v", second, err)
+		}
+
+		seen := map[uint]bool{}
+		for _, task := range append(first, second...) {
+			if seen[task.ID] {
+				t.Fatalf("task %d returned on both pages", task.ID)
+			}
+			seen[task.ID] = true
+		}
+
+		newest, _ := store.List(ctx, app.TaskFilter{}, app.ListOptions{Limit: 3})
+		cursor := &app.TaskCursor{ID: newest[2].ID, CreatedAt: newest[2].CreatedAt}
+		rest, err := store.List(ctx, app.TaskFilter{}, app.ListOptions{After: cursor})
+		if err != nil || len(rest) != 1 || rest[0].Title != "Buy milk" {
+			t.Fatalf("expected oldest task after cursor, got %+v (%v)", rest, err)
+		}
+	})
+}
+
+func TestStoreSearch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		for _, title := range []string{"Deploy backend to staging", "Deploy frontend deploy script", "Write docs"} {
+			if err := store.Create(ctx, &app.Task{Title: title}); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+
+		results, err := store.Search(ctx, "deploy", 10)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %+v", results)
+		}
+		if results[0].Title != "Deploy frontend deploy script" || results[0].Rank <= results[1].Rank {
+			t.Fatalf("expected the task with more hits ranked first, got %+v", results)
+		}
+		if results[1].Highlight != "<mark>Deploy</mark> backend to staging" {
+			t.Fatalf("unexpected highlight %q", results[1].Highlight)
+		}
+
+		results, _ = store.Search(ctx, "deploy docs", 10)
+		if len(results) != 0 {
+			t.Fatalf("expected every term to be required, got %+v", results)
+		}
+
+		// The text around the marks is escaped, for clients rendering them.
+		task := app.Task{Title: "<b>R&D</b> plan", Description: `<img src=x onerror="alert(1)"> R&D budget`}
+		if err := store.Create(ctx, &task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		results, _ = store.Search(ctx, "r&d", 10)
+		if len(results) != 1 || results[0].Highlight != "&lt;b&gt;<mark>R&amp;D</mark>&lt;/b&gt; plan" ||
+			results[0].Snippet != "&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>R&amp;D</mark> budget" {
+			t.Fatalf("expected escaped highlights, got %+v", results)
+		}
+	})
+}
+
+func TestStoreListDueAndPriority(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		past := time.Now().Add(-time.Hour)
+		future := time.Now().Add(time.Hour)
+		for _, task := range []app.Task{
+			{Title: "late", Priority: app.PriorityUrgent, DueAt: &past},
+			{Title: "late but done", Priority: app.PriorityHigh, DueAt: &past, Completed: true},
+			{Title: "upcoming", Priority: app.PriorityLow, DueAt: &future},
+			{Title: "someday", Priority: app.PriorityMedium},
+		} {
+			if err := store.Create(ctx, &task); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+
+		overdue := true
+		tasks, err := store.List(ctx, app.TaskFilter{Overdue: &overdue}, app.ListOptions{})
+		if err != nil || len(tasks) != 1 || tasks[0].Title != "late" {
+			t.Fatalf("expected only the open late task, got %+v (%v)", tasks, err)
+		}
+
+		overdue = false
+		if count, _ := store.Count(ctx, app.TaskFilter{Overdue: &overdue}); count != 3 {
+			t.Fatalf("expected 3 tasks not overdue, got %d", count)
+		}
+
+		tasks, _ = store.List(ctx, app.TaskFilter{Priorities: []app.Priority{app.PriorityHigh, app.PriorityUrgent}}, app.ListOptions{})
+		if len(tasks) != 2 {
+			t.Fatalf("expected 2 high or urgent tasks, got %+v", tasks)
+		}
+
+		now := time.Now()
+		if count, _ := store.Count(ctx, app.TaskFilter{DueAfter: &now}); count != 1 {
+			t.Fatalf("expected 1 task due in the future, got %d", count)
+		}
+	})
+}
+
+func TestStoreWorkspaceIsolation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		acme := app.WithWorkspace(context.Background(), 1)
+		other := app.WithWorkspace(context.Background(), 2)
+
+		task := app.Task{Title: "Deploy acme"}
+		if err := store.Create(acme, &task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if task.WorkspaceID != 1 {
+			t.Fatalf("expected the task in workspace 1, got %d", task.WorkspaceID)
+		}
+		if err := store.Create(other, &app.Task{Title: "Deploy other"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		if _, err := store.Get(other, task.ID); !errors.Is(err, app.ErrTaskNotFound) {
+			t.Fatalf("expected ErrTaskNotFound across workspaces, got %v", err)
+		}
+		tasks, err := store.List(acme, app.TaskFilter{}, app.ListOptions{})
+		if err != nil || len(tasks) != 1 || tasks[0].ID != task.ID {
+			t.Fatalf("expected only the acme task, got %+v (%v)", tasks, err)
+		}
+		if results, _ := store.Search(other, "acme", 10); len(results) != 0 {
+			t.Fatalf("expected no results across workspaces, got %+v", results)
+		}
+
+		if err := store.DeleteAll(other); err != nil {
+			t.Fatalf("delete all: %v", err)
+		}
+		if count, _ := store.Count(acme, app.TaskFilter{}); count != 1 {
+			t.Fatalf("expected the acme task to survive, got %d", count)
+		}
+		// Contexts without a workspace see every workspace.
+		if count, _ := store.Count(context.Background(), app.TaskFilter{}); count != 1 {
+			t.Fatalf("expected 1 task overall, got %d", count)
+		}
+	})
+}
+
+func TestStoreVersionConflict(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		task := app.Task{Title: "Contended"}
+		if err := store.Create(ctx, &task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if task.Version != 1 {
+			t.Fatalf("expected version 1, got %d", task.Version)
+		}
+
+		first, _ := store.Get(ctx, task.ID)
+		second, _ := store.Get(ctx, task.ID)
+
+		first.Completed = true
+		if err := store.Update(ctx, first); err != nil || first.Version != 2 {
+			t.Fatalf("update: version %d, %v", first.Version, err)
+		}
+		second.Title = "Overwritten"
+		if err := store.Update(ctx, second); !errors.Is(err, app.ErrVersionConflict) {
+			t.Fatalf("expected ErrVersionConflict, got %v", err)
+		}
+
+		got, _ := store.Get(ctx, task.ID)
+		if got.Title != "Contended" || !got.Completed || got.Version != 2 {
+			t.Fatalf("expected the first update only, got %+v", got)
+		}
+		if err := store.Update(ctx, &app.Task{ID: 42, Version: 1}); !errors.Is(err, app.ErrTaskNotFound) {
+			t.Fatalf("expected ErrTaskNotFound, got %v", err)
+		}
+	})
+}
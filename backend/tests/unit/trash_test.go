@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"taskboard-backend/app"
+)
+
+func TestTrashRestore(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Sprint"}`), &board)
+	todo, done := board.Columns[0].ID, board.Columns[2].ID
+
+	var tasks []app.Task
+	for _, title := range []string{"First", "Second", "Third"} {
+		w := doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":%q,"column_id":%d}`, title, todo))
+		tasks = append(tasks, decodeTask(t, w))
+	}
+	first := fmt.Sprintf("/api/tasks/%d", tasks[0].ID)
+
+	if w := doRequest(r, "DELETE", first, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := doRequest(r, "GET", first, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a trashed task, got %d", w.Code)
+	}
+	if got := listColumnTasks(t, r, todo); len(got) != 2 {
+		t.Fatalf("expected 2 tasks left in the column, got %d", len(got))
+	}
+
+	var trash []app.Task
+	decodeJSON(t, doRequest(r, "GET", "/api/trash", ""), &trash)
+	if len(trash) != 1 || trash[0].ID != tasks[0].ID || !trash[0].DeletedAt.Valid {
+		t.Fatalf("expected the deleted task in the trash, got %+v", trash)
+	}
+
+	// Meanwhile the task's column became a done column; the restored task
+	// goes to its bottom and is completed.
+	doRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/move", tasks[1].ID), fmt.Sprintf(`{"column_id":%d}`, done))
+	w := doRequest(r, "POST", first+"/restore", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	restored := decodeTask(t, w)
+	if restored.Position != 1 || restored.DeletedAt.Valid || restored.Version != tasks[0].Version+1 {
+		t.Fatalf("expected the task restored at position 1, got %+v", restored)
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf(`"%d"`, restored.Version) {
+		t.Fatalf("expected the ETag of the restored task, got %q", w.Header().Get("ETag"))
+	}
+	decodeJSON(t, doRequest(r, "GET", "/api/trash", ""), &trash)
+	if len(trash) != 0 {
+		t.Fatalf("expected an empty trash, got %+v", trash)
+	}
+	if w := doRequest(r, "POST", first+"/restore", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 restoring a task outside the trash, got %d", w.Code)
+	}
+
+	// A task whose column is gone comes back off the board.
+	column := fmt.Sprintf("/api/boards/%d/columns/%d", board.ID, done)
+	second := fmt.Sprintf("/api/tasks/%d", tasks[1].ID)
+	doRequest(r, "DELETE", second, "")
+	if w := doRequest(r, "DELETE", column, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected trashed tasks not to keep the column, got %d", w.Code)
+	}
+	restored = decodeTask(t, doRequest(r, "POST", second+"/restore", ""))
+	if restored.BoardID != nil || restored.ColumnID != nil {
+		t.Fatalf("expected the task off the board, got board %v column %v", restored.BoardID, restored.ColumnID)
+	}
+}
+
+func TestTrashRestorePositions(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	var board app.Board
+	decodeJSON(t, doRequest(r, "POST", "/api/boards", `{"name":"Sprint"}`), &board)
+	todo := board.Columns[0].ID
+
+	var ids []uint
+	for _, title := range []string{"First", "Second", "Third"} {
+		task := decodeTask(t, doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":%q,"column_id":%d}`, title, todo)))
+		ids = append(ids, task.ID)
+	}
+	second := fmt.Sprintf("/api/tasks/%d", ids[1])
+	doRequest(r, "DELETE", second, "")
+	if w := doRequest(r, "POST", second+"/restore", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The restored task goes to the bottom without taking anyone's slot.
+	tasks := listColumnTasks(t, r, todo)
+	if len(tasks) != 3 || tasks[0].ID != ids[0] || tasks[1].ID != ids[2] || tasks[2].ID != ids[1] {
+		t.Fatalf("expected the restored task last, got %+v", tasks)
+	}
+	checkPositions(t, tasks)
+}
+
+func TestTrashClearTasks(t *testing.T) {
+	r := newTestRouter()
+	doRequest(r, "POST", "/debug/generate-tasks?count=3", "")
+
+	if w := doRequest(r, "DELETE", "/debug/clear-tasks", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var tasks, trash []app.Task
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks", ""), &tasks)
+	if len(tasks) != 0 {
+		t.Fatalf("expected no tasks, got %d", len(tasks))
+	}
+	decodeJSON(t, doRequest(r, "GET", "/api/trash", ""), &trash)
+	if len(trash) != 3 {
+		t.Fatalf("expected the cleared tasks in the trash, got %d", len(trash))
+	}
+}
+
+func TestTrashRestoreRole(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, owner := registerUser(t, r, "Ada")
+	_, outsider := addTeammate(t, r, owner.AccessToken, "Bo")
+
+	var board app.Board
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/boards", `{"name":"Launch"}`, owner.AccessToken), &board)
+	task := decodeTask(t, doAuthRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Ship it","column_id":%d}`, board.Columns[0].ID), owner.AccessToken))
+	taskPath := fmt.Sprintf("/api/tasks/%d", task.ID)
+	doAuthRequest(r, "DELETE", taskPath, "", owner.AccessToken)
+
+	if w := doAuthRequest(r, "POST", taskPath+"/restore", "", outsider.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 restoring as a non-member, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", taskPath+"/restore", "", owner.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 restoring as owner, got %d", w.Code)
+	}
+}
+
+func TestStorePurge(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store app.TaskStore) {
+		ctx := context.Background()
+
+		kept, trashed := app.Task{Title: "Kept"}, app.Task{Title: "Trashed"}
+		for _, task := range []*app.Task{&kept, &trashed} {
+			if err := store.Create(ctx, task); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		before := time.Now().Add(-time.Minute)
+		if err := store.Delete(ctx, trashed.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+
+		if n, err := store.Purge(ctx, before); err != nil || n != 0 {
+			t.Fatalf("expected nothing purged before the deletion, got %d, %v", n, err)
+		}
+		if n, err := store.Purge(ctx, time.Now().Add(time.Minute)); err != nil || n != 1 {
+			t.Fatalf("expected 1 task purged, got %d, %v", n, err)
+		}
+		if _, err := store.Restore(ctx, trashed.ID); err != app.ErrTaskNotFound {
+			t.Fatalf("expected a purged task to be gone, got %v", err)
+		}
+		if _, err := store.Get(ctx, kept.ID); err != nil {
+			t.Fatalf("expected the live task to be kept, got %v", err)
+		}
+	})
+}
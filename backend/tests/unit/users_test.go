@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"taskboard-backend/app"
+)
+
+func createUser(t *testing.T, r http.Handler, name string) app.User {
+	t.Helper()
+	w := doRequest(r, "POST", "/api/users", fmt.Sprintf(`{"name":%q,"email":"%s@example.com"}`, name, strings.ToLower(name)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var user app.User
+	decodeJSON(t, w, &user)
+	return user
+}
+
+// listTasksAs lists tasks matching query with the given access token.
+func listTasksAs(t *testing.T, r *gin.Engine, token, query string) []app.Task {
+	t.Helper()
+	w := doAuthRequest(r, "GET", "/api/tasks?"+query, "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var tasks []app.Task
+	decodeJSON(t, w, &tasks)
+	return tasks
+}
+
+func TestUsers(t *testing.T) {
+	r := newSQLiteRouter(t)
+
+	ana := createUser(t, r, "Ana")
+	if w := doRequest(r, "POST", "/api/users", `{"name":"Other Ana","email":"ana@example.com"}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate email, got %d", w.Code)
+	}
+	if w := doRequest(r, "POST", "/api/users", `{"name":"Nobody","email":"not-an-email"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid email, got %d", w.Code)
+	}
+
+	w := doRequest(r, "PUT", fmt.Sprintf("/api/users/%d", ana.ID), `{"name":"Ana Lopez"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var users []app.User
+	decodeJSON(t, doRequest(r, "GET", "/api/users", ""), &users)
+	if len(users) != 1 || users[0].Name != "Ana Lopez" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+}
+
+func TestAssigneesAndWatchers(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+
+	ana, tokens := registerUser(t, r, "Ana")
+	bo, boTokens := addTeammate(t, r, tokens.AccessToken, "Bo")
+	token := tokens.AccessToken
+	doRequest := func(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+		return doAuthRequest(r, method, path, body, token)
+	}
+
+	for _, title := range []string{"Ana's task", "Bo's task", "Nobody's task"} {
+		doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":%q}`, title))
+	}
+
+	w := doRequest(r, "PUT", "/api/tasks/1/assignee", fmt.Sprintf(`{"user_id":%d}`, ana.ID))
+	task := decodeTask(t, w)
+	if task.AssigneeID == nil || *task.AssigneeID != ana.ID || task.Assignee == nil || task.Assignee.Name != "Ana" {
+		t.Fatalf("expected task assigned to Ana, got %+v", task)
+	}
+	doRequest(r, "PUT", "/api/tasks/2/assignee", fmt.Sprintf(`{"user_id":%d}`, bo.ID))
+	if w := doRequest(r, "PUT", "/api/tasks/3/assignee", `{"user_id":99}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown user, got %d", w.Code)
+	}
+
+	if tasks := listTasksAs(t, r, token, "assignee=me"); len(tasks) != 1 || tasks[0].ID != 1 {
+		t.Fatalf("expected Ana's task for assignee=me, got %+v", tasks)
+	}
+	if tasks := listTasksAs(t, r, token, fmt.Sprintf("assignee=%d", bo.ID)); len(tasks) != 1 || tasks[0].ID != 2 {
+		t.Fatalf("expected Bo's task, got %+v", tasks)
+	}
+	if tasks := listTasksAs(t, r, token, "assignee=none"); len(tasks) != 1 || tasks[0].ID != 3 {
+		t.Fatalf("expected the unassigned task, got %+v", tasks)
+	}
+
+	task = decodeTask(t, doRequest(r, "DELETE", "/api/tasks/1/assignee", ""))
+	if task.AssigneeID != nil || task.Assignee != nil {
+		t.Fatalf("expected task to be unassigned, got %+v", task)
+	}
+
+	doRequest(r, "POST", "/api/tasks/3/watchers", fmt.Sprintf(`{"user_id":%d}`, bo.ID))
+	task = decodeTask(t, doRequest(r, "POST", "/api/tasks/3/watchers", fmt.Sprintf(`{"user_id":%d}`, ana.ID)))
+	if len(task.Watchers) != 2 || task.Watchers[0].Name != "Ana" {
+		t.Fatalf("expected two watchers ordered by name, got %+v", task.Watchers)
+	}
+	task = decodeTask(t, doRequest(r, "DELETE", fmt.Sprintf("/api/tasks/3/watchers/%d", ana.ID), ""))
+	if len(task.Watchers) != 1 || task.Watchers[0].ID != bo.ID {
+		t.Fatalf("expected only Bo watching, got %+v", task.Watchers)
+	}
+	// Watching again changes nothing, so it leaves the version alone.
+	if again := decodeTask(t, doRequest(r, "POST", "/api/tasks/3/watchers", fmt.Sprintf(`{"user_id":%d}`, bo.ID))); again.Version != task.Version {
+		t.Fatalf("expected version %d for an unchanged task, got %d", task.Version, again.Version)
+	}
+
+	// Watches are versioned and recorded like other changes.
+	var events []app.TaskEvent
+	decodeJSON(t, doRequest(r, "GET", "/api/tasks/3/history", ""), &events)
+	if len(events) != 4 || task.Version != 4 {
+		t.Fatalf("expected three watch events at version 4, got %+v (version %d)", events, task.Version)
+	}
+	if c := events[0].Changes; len(c) != 1 || c[0].Field != "watcher_ids" || string(c[0].After) != fmt.Sprintf("[%d]", bo.ID) {
+		t.Fatalf("expected the watcher change, got %+v", c)
+	}
+
+	// Deleting a user unassigns their tasks and drops their watches.
+	if w := doAuthRequest(r, "DELETE", fmt.Sprintf("/api/users/%d", bo.ID), "", boTokens.AccessToken); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if tasks := listTasksAs(t, r, token, "assignee=none"); len(tasks) != 3 || len(tasks[0].Watchers) != 0 {
+		t.Fatalf("expected all tasks unassigned and unwatched, got %+v", tasks)
+	}
+}
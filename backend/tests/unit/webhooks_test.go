@@ -0,0 +1,254 @@
+package unit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"taskboard-backend/app"
+)
+
+// webhookReceiver records the requests it receives, failing the first one.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	headers  []http.Header
+	bodies   [][]byte
+	failures int
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.headers = append(wr.headers, r.Header)
+	wr.bodies = append(wr.bodies, body)
+	if wr.failures > 0 {
+		wr.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+func listDeliveries(t *testing.T, r http.Handler, token string, webhookID uint) []app.WebhookDelivery {
+	t.Helper()
+	var deliveries []app.WebhookDelivery
+	decodeJSON(t, doAuthRequest(r, "GET", fmt.Sprintf("/api/webhooks/%d/deliveries", webhookID), "", token), &deliveries)
+	return deliveries
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stores := app.NewGormStores(newSQLiteDB(t))
+	auth, err := app.NewAuthenticator(testAuthConfig())
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server := app.NewServer(stores, auth)
+	// The receiver listens on the loopback interface.
+	server.AllowPrivateWebhookTargets()
+	startRelay(t, server)
+	r := server.Router()
+	_, ada := registerUser(t, r, "Ada")
+	_, zed := registerUser(t, r, "Zed")
+
+	receiver := &webhookReceiver{failures: 1}
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+
+	const secret = "correct horse battery"
+	w := doAuthRequest(r, "POST", "/api/webhooks", fmt.Sprintf(`{"url":%q,"events":["task.created"],"secret":%q}`, srv.URL, secret), ada.AccessToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var webhook app.Webhook
+	decodeJSON(t, w, &webhook)
+	if w := doAuthRequest(r, "POST", "/api/webhooks", `{"url":"ftp://example.com","secret":"correct horse battery"}`, ada.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a non-HTTP URL, got %d", w.Code)
+	}
+
+	// Only Ada's workspace and the subscribed events are delivered.
+	task := decodeTask(t, doAuthRequest(r, "POST", "/api/tasks", `{"title":"Ship"}`, ada.AccessToken))
+	doAuthRequest(r, "PATCH", fmt.Sprintf("/api/tasks/%d", task.ID), `{"title":"Ship it"}`, ada.AccessToken)
+	doAuthRequest(r, "POST", "/api/tasks", `{"title":"Elsewhere"}`, zed.AccessToken)
+
+	// The relay queues the deliveries once the changes are committed.
+	waitFor(t, func() bool {
+		return len(listDeliveries(t, r, ada.AccessToken, webhook.ID)) == 1
+	})
+
+	dispatcher := app.NewWebhookDispatcher(stores.Webhooks, app.WebhookConfig{
+		Timeout:             time.Second,
+		MaxAttempts:         3,
+		RetryBackoff:        100 * time.Millisecond,
+		AllowPrivateTargets: true,
+	})
+	ctx := context.Background()
+	if n, err := dispatcher.DeliverDue(ctx); err != nil || n != 1 {
+		t.Fatalf("expected one attempt, got %d (%v)", n, err)
+	}
+	deliveries := listDeliveries(t, r, ada.AccessToken, webhook.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("expected one delivery, got %+v", deliveries)
+	}
+	if d := deliveries[0]; d.Status != app.DeliveryPending || d.Attempts != 1 || d.ResponseStatus != http.StatusServiceUnavailable || !d.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("expected a pending retry after the 503, got %+v", d)
+	}
+
+	// The retry waits for its backoff.
+	if n, _ := dispatcher.DeliverDue(ctx); n != 0 {
+		t.Fatalf("expected no attempt before the backoff, got %d", n)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if n, err := dispatcher.DeliverDue(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the retry, got %d (%v)", n, err)
+	}
+	if d := listDeliveries(t, r, ada.AccessToken, webhook.ID)[0]; d.Status != app.DeliveryDelivered || d.Attempts != 2 || d.DeliveredAt == nil {
+		t.Fatalf("expected the delivery to succeed, got %+v", d)
+	}
+
+	body, header := receiver.bodies[1], receiver.headers[1]
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if got, want := header.Get("X-TaskBoard-Signature"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("expected signature %s, got %s", want, got)
+	}
+	var payload app.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.Event != app.TaskCreated || header.Get("X-TaskBoard-Event") != app.TaskCreated || payload.Task == nil || payload.Task.Title != "Ship" {
+		t.Fatalf("expected the task.created payload, got %s", body)
+	}
+
+	path := fmt.Sprintf("/api/webhooks/%d", webhook.ID)
+	if w := doAuthRequest(r, "GET", path+"/deliveries", "", zed.AccessToken); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for another workspace's webhook, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "DELETE", path, "", ada.AccessToken); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "GET", path, "", ada.AccessToken); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 once deleted, got %d", w.Code)
+	}
+}
+
+func TestWebhookTargets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stores := app.NewGormStores(newSQLiteDB(t))
+	auth, err := app.NewAuthenticator(testAuthConfig())
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server := app.NewServer(stores, auth)
+	startRelay(t, server)
+	r := server.Router()
+	_, ada := registerUser(t, r, "Ada")
+	_, bo := addTeammate(t, r, ada.AccessToken, "Bo")
+
+	// Webhooks see every task of the workspace: only owners manage them.
+	public := `{"url":"http://203.0.113.10/hook","secret":"correct horse battery"}`
+	if w := doAuthRequest(r, "POST", "/api/webhooks", public, bo.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an editor, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "GET", "/api/webhooks", "", bo.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an editor listing webhooks, got %d", w.Code)
+	}
+	w := doAuthRequest(r, "POST", "/api/webhooks", public, ada.AccessToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a public address, got %d: %s", w.Code, w.Body.String())
+	}
+	var webhook app.Webhook
+	decodeJSON(t, w, &webhook)
+	doAuthRequest(r, "DELETE", fmt.Sprintf("/api/webhooks/%d", webhook.ID), "", ada.AccessToken)
+
+	for _, target := range []string{"http://127.0.0.1:8080/", "http://localhost/", "http://10.1.2.3/", "http://169.254.169.254/latest", "http://[::1]/", "http://[::ffff:192.168.0.1]/"} {
+		w := doAuthRequest(r, "POST", "/api/webhooks", fmt.Sprintf(`{"url":%q,"secret":"correct horse battery"}`, target), ada.AccessToken)
+		var problem app.Problem
+		decodeJSON(t, w, &problem)
+		if w.Code != http.StatusBadRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "url" {
+			t.Fatalf("expected a url field error for %s, got %d %s", target, w.Code, w.Body.String())
+		}
+	}
+
+	// The dispatcher checks the addresses it connects to as well, which
+	// covers redirects and hosts resolving differently later on.
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+	server.AllowPrivateWebhookTargets()
+	w = doAuthRequest(r, "POST", "/api/webhooks", fmt.Sprintf(`{"url":%q,"events":["task.created"],"secret":"correct horse battery"}`, srv.URL), ada.AccessToken)
+	decodeJSON(t, w, &webhook)
+	doAuthRequest(r, "POST", "/api/tasks", `{"title":"Ship"}`, ada.AccessToken)
+	waitFor(t, func() bool {
+		return len(listDeliveries(t, r, ada.AccessToken, webhook.ID)) == 1
+	})
+
+	dispatcher := app.NewWebhookDispatcher(stores.Webhooks, app.WebhookConfig{Timeout: time.Second, MaxAttempts: 3, RetryBackoff: time.Minute})
+	if n, err := dispatcher.DeliverDue(context.Background()); err != nil || n != 1 {
+		t.Fatalf("expected one attempt, got %d (%v)", n, err)
+	}
+	if d := listDeliveries(t, r, ada.AccessToken, webhook.ID)[0]; d.Status != app.DeliveryPending || !strings.Contains(d.LastError, "private address") {
+		t.Fatalf("expected the delivery refused, got %+v", d)
+	}
+	if len(receiver.bodies) != 0 {
+		t.Fatalf("expected nothing delivered, got %d requests", len(receiver.bodies))
+	}
+}
+
+func TestWebhookBatchWithinLease(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stores := app.NewGormStores(newSQLiteDB(t))
+	server := app.NewServer(stores, nil)
+	server.AllowPrivateWebhookTargets()
+	startRelay(t, server)
+	r := server.Router()
+
+	// Each delivery takes most of the timeout: attempted one after the
+	// other, the last ones would outlive the claim.
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		receiver.ServeHTTP(w, req)
+	}))
+	t.Cleanup(srv.Close)
+
+	var webhook app.Webhook
+	decodeJSON(t, doRequest(r, "POST", "/api/webhooks", fmt.Sprintf(`{"url":%q,"secret":"correct horse battery"}`, srv.URL)), &webhook)
+	for i := 0; i < 5; i++ {
+		doRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Task %d"}`, i))
+	}
+	waitFor(t, func() bool {
+		return len(listDeliveries(t, r, "", webhook.ID)) == 5
+	})
+
+	cfg := app.WebhookConfig{Timeout: 200 * time.Millisecond, MaxAttempts: 3, RetryBackoff: time.Minute, AllowPrivateTargets: true}
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for _, delay := range []time.Duration{0, 500 * time.Millisecond} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The second replica looks once the first one's claim expired.
+			time.Sleep(delay)
+			app.NewWebhookDispatcher(stores.Webhooks, cfg).DeliverDue(ctx)
+		}()
+	}
+	wg.Wait()
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.bodies) != 5 {
+		t.Fatalf("expected each delivery once, got %d requests", len(receiver.bodies))
+	}
+}
@@ -0,0 +1,197 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"taskboard-backend/app"
+)
+
+func TestWorkspaceIsolation(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, ada := registerUser(t, r, "Ada")
+	zed, zedTokens := registerUser(t, r, "Zed")
+	other := zedTokens.AccessToken
+
+	// Zed fills their workspace.
+	var board app.Board
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/boards", `{"name":"Secret plans"}`, other), &board)
+	w := doAuthRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"Secret task","column_id":%d}`, board.Columns[0].ID), other)
+	secret := decodeTask(t, w)
+	var label app.Label
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/labels", `{"name":"bug"}`, other), &label)
+	doAuthRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/checklist", secret.ID), `{"title":"Step"}`, other)
+	doAuthRequest(r, "POST", fmt.Sprintf("/api/tasks/%d/comments", secret.ID), `{"body":"Hush"}`, other)
+
+	// Ada sees none of it.
+	for _, path := range []string{"/api/tasks", "/api/tasks/search?q=secret", "/api/boards", "/api/labels"} {
+		w := doAuthRequest(r, "GET", path, "", ada.AccessToken)
+		if w.Code != http.StatusOK || w.Body.String() != "[]" {
+			t.Errorf("GET %s: expected an empty list, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	var users []app.User
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/users", "", ada.AccessToken), &users)
+	if len(users) != 1 || users[0].Name != "Ada" {
+		t.Errorf("expected only Ada's workspace users, got %+v", users)
+	}
+
+	taskPath := fmt.Sprintf("/api/tasks/%d", secret.ID)
+	boardPath := fmt.Sprintf("/api/boards/%d", board.ID)
+	for _, req := range []struct{ method, path, body string }{
+		{"PUT", taskPath, `{"title":"Mine"}`},
+		{"POST", taskPath + "/move", fmt.Sprintf(`{"column_id":%d}`, board.Columns[1].ID)},
+		{"GET", taskPath + "/checklist", ""},
+		{"POST", taskPath + "/checklist", `{"title":"Sneaky"}`},
+		{"PUT", taskPath + "/checklist/1", `{"done":true}`},
+		{"GET", taskPath + "/comments", ""},
+		{"PUT", taskPath + "/comments/1", `{"body":"Hijacked"}`},
+		{"POST", taskPath + "/labels", fmt.Sprintf(`{"label_ids":[%d]}`, label.ID)},
+		{"PUT", taskPath + "/assignee", fmt.Sprintf(`{"user_id":%d}`, zed.ID)},
+		{"GET", boardPath, ""},
+		{"PUT", boardPath, `{"name":"Mine"}`},
+		{"GET", boardPath + "/columns", ""},
+		{"GET", boardPath + "/members", ""},
+		{"GET", fmt.Sprintf("/api/labels/%d", label.ID), ""},
+		{"PUT", fmt.Sprintf("/api/labels/%d", label.ID), `{"name":"mine"}`},
+		{"DELETE", fmt.Sprintf("/api/labels/%d", label.ID), ""},
+		{"GET", fmt.Sprintf("/api/users/%d", zed.ID), ""},
+	} {
+		if w := doAuthRequest(r, req.method, req.path, req.body, ada.AccessToken); w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected 404, got %d %s", req.method, req.path, w.Code, w.Body.String())
+		}
+	}
+
+	// Ada's own tasks cannot reach into Zed's workspace either.
+	mine := decodeTask(t, doAuthRequest(r, "POST", "/api/tasks", `{"title":"Mine"}`, ada.AccessToken))
+	minePath := fmt.Sprintf("/api/tasks/%d", mine.ID)
+	if w := doAuthRequest(r, "POST", minePath+"/labels", fmt.Sprintf(`{"label_ids":[%d]}`, label.ID), ada.AccessToken); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 attaching a foreign label, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "PUT", minePath+"/assignee", fmt.Sprintf(`{"user_id":%d}`, zed.ID), ada.AccessToken); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 assigning a foreign user, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "POST", minePath+"/move", fmt.Sprintf(`{"column_id":%d}`, board.Columns[0].ID), ada.AccessToken); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 moving to a foreign column, got %d", w.Code)
+	}
+
+	// Label names are only unique within a workspace.
+	if w := doAuthRequest(r, "POST", "/api/labels", `{"name":"bug"}`, ada.AccessToken); w.Code != http.StatusCreated {
+		t.Errorf("expected 201 reusing a label name, got %d", w.Code)
+	}
+
+	// Deleting and clearing only affects Ada's rows.
+	doAuthRequest(r, "DELETE", taskPath, "", ada.AccessToken)
+	doAuthRequest(r, "DELETE", "/debug/clear-tasks", "", ada.AccessToken)
+	tasks := listTasksAs(t, r, other, "")
+	if len(tasks) != 1 || tasks[0].Title != "Secret task" {
+		t.Fatalf("expected Zed's task to survive, got %+v", tasks)
+	}
+	if tasks[0].Progress == nil || tasks[0].CommentCount != 1 {
+		t.Fatalf("expected Zed's checklist and comment to survive, got %+v", tasks[0])
+	}
+}
+
+func TestWorkspaceDebugStats(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, ada := registerUser(t, r, "Ada")
+	_, zed := registerUser(t, r, "Zed")
+
+	if w := doRequest(r, "GET", "/debug/stats", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", w.Code)
+	}
+
+	doAuthRequest(r, "POST", "/debug/generate-tasks?count=3", "", zed.AccessToken)
+	doAuthRequest(r, "POST", "/api/tasks", `{"title":"Mine"}`, ada.AccessToken)
+
+	var stats struct {
+		Tasks struct{ Count int64 } `json:"tasks"`
+	}
+	decodeJSON(t, doAuthRequest(r, "GET", "/debug/stats", "", ada.AccessToken), &stats)
+	if stats.Tasks.Count != 1 {
+		t.Fatalf("expected Ada's stats to count 1 task, got %d", stats.Tasks.Count)
+	}
+	decodeJSON(t, doAuthRequest(r, "GET", "/debug/stats", "", zed.AccessToken), &stats)
+	if stats.Tasks.Count != 3 {
+		t.Fatalf("expected Zed's stats to count 3 tasks, got %d", stats.Tasks.Count)
+	}
+}
+
+func TestWorkspaceSettings(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	w := doRequest(r, "POST", "/api/auth/register",
+		`{"name":"Ada","email":"ada@example.com","password":"correct horse","workspace":"Acme"}`)
+	var tokens app.TokenPair
+	decodeJSON(t, w, &tokens)
+
+	var workspace app.Workspace
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/workspace", "", tokens.AccessToken), &workspace)
+	if workspace.Name != "Acme" {
+		t.Fatalf("expected workspace Acme, got %+v", workspace)
+	}
+
+	w = doAuthRequest(r, "PUT", "/api/workspace", `{"name":"Acme Inc"}`, tokens.AccessToken)
+	decodeJSON(t, w, &workspace)
+	if w.Code != http.StatusOK || workspace.Name != "Acme Inc" {
+		t.Fatalf("expected the workspace renamed, got %d %+v", w.Code, workspace)
+	}
+
+	// Teammates join the workspace of the user who creates them.
+	bo, boTokens := addTeammate(t, r, tokens.AccessToken, "Bo")
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/workspace", "", boTokens.AccessToken), &workspace)
+	if workspace.Name != "Acme Inc" {
+		t.Fatalf("expected Bo in Acme Inc, got %+v", workspace)
+	}
+	var users []app.User
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/users", "", tokens.AccessToken), &users)
+	if len(users) != 2 || users[1].ID != bo.ID {
+		t.Fatalf("expected Ada and Bo, got %+v", users)
+	}
+}
+
+func TestEmailPerWorkspace(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, ada := registerUser(t, r, "Ada")
+	_, zed := registerUser(t, r, "Zed")
+
+	// Zed adding Ada's email to their workspace learns nothing about Ada.
+	body := `{"name":"Ada","email":"ada@example.com","password":"other horse"}`
+	w := doAuthRequest(r, "POST", "/api/users", body, zed.AccessToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 reusing an email of another workspace, got %d: %s", w.Code, w.Body.String())
+	}
+	var zeds app.User
+	decodeJSON(t, w, &zeds)
+	if w := doAuthRequest(r, "POST", "/api/users", body, zed.AccessToken); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for an email taken in the workspace, got %d", w.Code)
+	}
+
+	// Each password logs in to its own workspace.
+	var tokens app.TokenPair
+	var me app.User
+	decodeJSON(t, doRequest(r, "POST", "/api/auth/login", `{"email":"ada@example.com","password":"other horse"}`), &tokens)
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/me", "", tokens.AccessToken), &me)
+	if me.ID != zeds.ID {
+		t.Fatalf("expected to log in as the user in Zed's workspace, got %+v", me)
+	}
+
+	// The same password in both asks which workspace to use.
+	var own app.User
+	var workspace app.Workspace
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/me", "", ada.AccessToken), &own)
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/workspace", "", ada.AccessToken), &workspace)
+	if w := doAuthRequest(r, "PUT", fmt.Sprintf("/api/users/%d", zeds.ID), `{"password":"correct horse"}`, tokens.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 changing the password, got %d: %s", w.Code, w.Body.String())
+	}
+	login := `{"email":"ada@example.com","password":"correct horse"}`
+	if w := doRequest(r, "POST", "/api/auth/login", login); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a login matching two workspaces, got %d", w.Code)
+	}
+	login = fmt.Sprintf(`{"email":"ada@example.com","password":"correct horse","workspace_id":%d}`, workspace.ID)
+	decodeJSON(t, doRequest(r, "POST", "/api/auth/login", login), &tokens)
+	decodeJSON(t, doAuthRequest(r, "GET", "/api/me", "", tokens.AccessToken), &me)
+	if me.ID != own.ID {
+		t.Fatalf("expected to log in to Ada's own workspace, got %+v", me)
+	}
+}
@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"taskboard-backend/app"
+)
+
+// dialWS opens /api/ws on srv, authenticating with token the way browsers
+// do, through the bearer subprotocol.
+func dialWS(t *testing.T, srv *httptest.Server, token string) *websocket.Conn {
+	t.Helper()
+	config, _ := websocket.NewConfig("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", srv.URL)
+	config.Protocol = []string{"bearer", token}
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("failed to open the WebSocket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func sendWS(t *testing.T, conn *websocket.Conn, body string) {
+	t.Helper()
+	if err := websocket.Message.Send(conn, body); err != nil {
+		t.Fatalf("failed to send %s: %v", body, err)
+	}
+}
+
+func receiveWS(t *testing.T, conn *websocket.Conn) app.WSMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg app.WSMessage
+	if err := websocket.JSON.Receive(conn, &msg); err != nil {
+		t.Fatalf("failed to receive a message: %v", err)
+	}
+	return msg
+}
+
+// presence formats the users of a presence message as name:editing.
+func presence(msg app.WSMessage) string {
+	var users []string
+	for _, user := range msg.Users {
+		editing := "-"
+		if user.Editing != nil {
+			editing = fmt.Sprint(*user.Editing)
+		}
+		users = append(users, user.Name+":"+editing)
+	}
+	return msg.Type + " " + strings.Join(users, " ")
+}
+
+func TestWebSocket(t *testing.T) {
+	r := newAuthRouter(t, testAuthConfig())
+	_, ada := registerUser(t, r, "Ada")
+	_, bob := addTeammate(t, r, ada.AccessToken, "Bob")
+
+	var board app.Board
+	decodeJSON(t, doAuthRequest(r, "POST", "/api/boards", `{"name":"Sprint"}`, ada.AccessToken), &board)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	adaWS := dialWS(t, srv, ada.AccessToken)
+	sendWS(t, adaWS, fmt.Sprintf(`{"type":"subscribe","board_id":%d}`, board.ID))
+	if got := presence(receiveWS(t, adaWS)); got != "presence Ada:-" {
+		t.Fatalf("expected Ada alone on the board, got %q", got)
+	}
+
+	bobWS := dialWS(t, srv, bob.AccessToken)
+	sendWS(t, bobWS, fmt.Sprintf(`{"type":"subscribe","board_id":%d}`, board.ID))
+	for _, conn := range []*websocket.Conn{adaWS, bobWS} {
+		if got := presence(receiveWS(t, conn)); got != "presence Ada:- Bob:-" {
+			t.Fatalf("expected Ada and Bob on the board, got %q", got)
+		}
+	}
+
+	// Only changes to tasks on followed boards are sent.
+	doAuthRequest(r, "POST", "/api/tasks", `{"title":"Elsewhere"}`, ada.AccessToken)
+	task := decodeTask(t, doAuthRequest(r, "POST", "/api/tasks", fmt.Sprintf(`{"title":"On the board","column_id":%d}`, board.Columns[0].ID), ada.AccessToken))
+	msg := receiveWS(t, bobWS)
+	if msg.Type != "task.created" || msg.BoardID != board.ID || msg.Task == nil || msg.Task.ID != task.ID || msg.EventID == "" {
+		t.Fatalf("expected task.created for the task on the board, got %+v", msg)
+	}
+	receiveWS(t, adaWS)
+
+	sendWS(t, bobWS, fmt.Sprintf(`{"type":"presence","board_id":%d,"editing":%d}`, board.ID, task.ID))
+	want := fmt.Sprintf("presence Ada:- Bob:%d", task.ID)
+	if got := presence(receiveWS(t, adaWS)); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	receiveWS(t, bobWS)
+
+	sendWS(t, bobWS, `{"type":"subscribe","board_id":999}`)
+	if msg := receiveWS(t, bobWS); msg.Type != "error" || msg.Detail != "board not found" {
+		t.Fatalf("expected an error for an unknown board, got %+v", msg)
+	}
+	sendWS(t, bobWS, `not json`)
+	if msg := receiveWS(t, bobWS); msg.Type != "error" {
+		t.Fatalf("expected an error for an invalid message, got %+v", msg)
+	}
+
+	bobWS.Close()
+	if got := presence(receiveWS(t, adaWS)); got != "presence Ada:-" {
+		t.Fatalf("expected Bob gone once disconnected, got %q", got)
+	}
+
+	if w := doAuthRequest(r, "GET", "/api/ws", "", ada.AccessToken); w.Code != http.StatusUpgradeRequired {
+		t.Fatalf("expected 426 without a WebSocket handshake, got %d", w.Code)
+	}
+	config, _ := websocket.NewConfig("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", srv.URL)
+	if _, err := websocket.DialConfig(config); err == nil {
+		t.Fatal("expected the handshake to fail without a token")
+	}
+}
@@ -1,82 +1,155 @@
 import React, { useEffect, useState } from 'react'
+import { API_URL, UnauthorizedError, apiFetch, getTokens, setTokens, streamEvents } from './api'
+import Login from './Login'
+
+type Priority = 'low' | 'medium' | 'high' | 'urgent'
+
+type Label = {
+  id: number
+  name: string
+  color: string
+}
+
+type User = {
+  id: number
+  name: string
+}
 
 type Task = {
   id: number
   title: string
+  description: string
+  priority: Priority
+  due_at: string | null
+  labels: Label[] | null
+  progress?: { done: number; total: number }
+  comment_count: number
+  assignee?: User
   completed: boolean
   created_at: string
 }
 
-//@ts-expect-error any
-const API_URL = window._env_?.VITE_API_URL || 'http://localhost:8080';
+const PRIORITY_COLORS: Record<Priority, string> = {
+  low: '#64748b',
+  medium: '#3b82f6',
+  high: '#f59e0b',
+  urgent: '#ef4444',
+}
+
+const isOverdue = (task: Task) =>
+  !task.completed && task.due_at !== null && new Date(task.due_at) < new Date()
 
 const App: React.FC = () => {
+  const [loggedIn, setLoggedIn] = useState(getTokens() !== null)
   const [tasks, setTasks] = useState<Task[]>([])
   const [newTitle, setNewTitle] = useState('')
   const [loading, setLoading] = useState(false)
   const [error, setError] = useState<string | null>(null)
 
+  // reportError shows err, returning to the login form when the session ended.
+  const reportError = (err: any, fallback: string) => {
+    if (err instanceof UnauthorizedError) setLoggedIn(false)
+    setError(err.message || fallback)
+  }
+
+  const logout = () => {
+    setTokens(null)
+    setLoggedIn(false)
+    setTasks([])
+  }
+
   const fetchTasks = async () => {
     setLoading(true)
     setError(null)
     try {
-      const res = await fetch(`${API_URL}/api/tasks`)
-      if (!res.ok) throw new Error('Failed to load tasks')
-      const data = await res.json()
-      setTasks(data)
+      // The API is paginated: keep following the Link rel="next" header.
+      const all: Task[] = []
+      let url: string | null = `${API_URL}/api/tasks`
+      while (url) {
+        const res: Response = await apiFetch(url)
+        if (!res.ok) throw new Error('Failed to load tasks')
+        all.push(...(await res.json()))
+        const next = res.headers.get('Link')?.match(/<([^>]+)>;\s*rel="next"/)
+        url = next ? `${API_URL}${next[1]}` : null
+      }
+      setTasks(all)
     } catch (err: any) {
-      setError(err.message || 'Error fetching tasks')
+      reportError(err, 'Error fetching tasks')
     } finally {
       setLoading(false)
     }
   }
 
   useEffect(() => {
-    fetchTasks()
-  }, [])
+    if (loggedIn) fetchTasks()
+  }, [loggedIn])
+
+  // Keep the list in sync with changes made elsewhere, e.g. in other tabs.
+  useEffect(() => {
+    if (!loggedIn) return
+    const controller = new AbortController()
+    streamEvents(({ type, data }) => {
+      switch (type) {
+        case 'task.created':
+        case 'task.restored':
+          setTasks(prev => (prev.some(t => t.id === data.id) ? prev : [data, ...prev]))
+          break
+        case 'task.updated':
+          setTasks(prev => prev.map(t => (t.id === data.id ? { ...t, ...data } : t)))
+          break
+        case 'task.deleted':
+          setTasks(prev => prev.filter(t => t.id !== data.id))
+          break
+        case 'reset':
+          fetchTasks()
+          break
+      }
+    }, controller.signal)
+    return () => controller.abort()
+  }, [loggedIn])
 
   const handleAdd = async (e: React.FormEvent) => {
     e.preventDefault()
     if (!newTitle.trim()) return
     try {
-      const res = await fetch(`${API_URL}/api/tasks`, {
+      const res = await apiFetch(`${API_URL}/api/tasks`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({ title: newTitle }),
       })
       if (!res.ok) throw new Error('Failed to create task')
       const created = await res.json()
-      setTasks(prev => [created, ...prev])
+      setTasks(prev => [created, ...prev.filter(t => t.id !== created.id)])
       setNewTitle('')
     } catch (err: any) {
-      setError(err.message || 'Error creating task')
+      reportError(err, 'Error creating task')
     }
   }
 
   const toggleCompleted = async (task: Task) => {
     try {
-      const res = await fetch(`${API_URL}/api/tasks/${task.id}`, {
-        method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
+      const res = await apiFetch(`${API_URL}/api/tasks/${task.id}`, {
+        method: 'PATCH',
+        headers: { 'Content-Type': 'application/merge-patch+json' },
         body: JSON.stringify({ completed: !task.completed }),
       })
       if (!res.ok) throw new Error('Failed to update task')
       const updated = await res.json()
       setTasks(prev => prev.map(t => (t.id === updated.id ? updated : t)))
     } catch (err: any) {
-      setError(err.message || 'Error updating task')
+      reportError(err, 'Error updating task')
     }
   }
 
   const deleteTask = async (id: number) => {
     try {
-      const res = await fetch(`${API_URL}/api/tasks/${id}`, {
+      const res = await apiFetch(`${API_URL}/api/tasks/${id}`, {
         method: 'DELETE',
       })
       if (!res.ok && res.status !== 204) throw new Error('Failed to delete task')
       setTasks(prev => prev.filter(t => t.id !== id))
     } catch (err: any) {
-      setError(err.message || 'Error deleting task')
+      reportError(err, 'Error deleting task')
     }
   }
 
@@ -104,6 +177,20 @@ const App: React.FC = () => {
           Demo app (Go + React + PostgreSQL) for CI/CD pipelines.
         </p>
 
+        {!loggedIn ? (
+          <>
+            {error && <p style={{ color: '#fca5a5', fontSize: '0.85rem' }}>{error}</p>}
+            <Login onLogin={() => { setError(null); setLoggedIn(true) }} />
+          </>
+        ) : (
+        <>
+        <button
+          onClick={logout}
+          style={{ border: 'none', background: 'transparent', color: '#9ca3af', cursor: 'pointer', fontSize: '0.85rem', marginBottom: '0.5rem', padding: 0 }}
+        >
+          Log out
+        </button>
+
         <form onSubmit={handleAdd} style={{ display: 'flex', gap: '0.5rem', marginBottom: '1rem' }}>
           <input
             type="text"
@@ -171,12 +258,56 @@ const App: React.FC = () => {
                     checked={task.completed}
                     onChange={() => toggleCompleted(task)}
                   />
-                  <span style={{
-                    textDecoration: task.completed ? 'line-through' : 'none',
-                    color: task.completed ? '#6b7280' : '#e5e7eb'
-                  }}>
-                    {task.title}
-                  </span>
+                  <div style={{ display: 'flex', flexDirection: 'column', gap: '0.2rem' }}>
+                    <span style={{
+                      textDecoration: task.completed ? 'line-through' : 'none',
+                      color: task.completed ? '#6b7280' : '#e5e7eb'
+                    }}>
+                      {task.title}
+                      <span style={{
+                        marginLeft: '0.5rem',
+                        padding: '0.05rem 0.4rem',
+                        borderRadius: '0.4rem',
+                        fontSize: '0.7rem',
+                        background: PRIORITY_COLORS[task.priority] ?? PRIORITY_COLORS.medium,
+                        color: 'white'
+                      }}>
+                        {task.priority}
+                      </span>
+                    </span>
+                    {task.labels && task.labels.length > 0 && (
+                      <span style={{ display: 'flex', gap: '0.3rem', flexWrap: 'wrap' }}>
+                        {task.labels.map(label => (
+                          <span key={label.id} style={{
+                            padding: '0.05rem 0.4rem',
+                            borderRadius: '0.4rem',
+                            fontSize: '0.7rem',
+                            border: `1px solid ${label.color}`,
+                            color: label.color
+                          }}>
+                            {label.name}
+                          </span>
+                        ))}
+                      </span>
+                    )}
+                    {(task.progress || task.comment_count > 0 || task.assignee) && (
+                      <span style={{ fontSize: '0.75rem', color: '#9ca3af', display: 'flex', gap: '0.6rem' }}>
+                        {task.progress && <span>{task.progress.done}/{task.progress.total} done</span>}
+                        {task.comment_count > 0 && <span>💬 {task.comment_count}</span>}
+                        {task.assignee && <span>👤 {task.assignee.name}</span>}
+                      </span>
+                    )}
+                    {task.description && (
+                      <span style={{ fontSize: '0.8rem', color: '#9ca3af', whiteSpace: 'pre-wrap' }}>
+                        {task.description}
+                      </span>
+                    )}
+                    {task.due_at && (
+                      <span style={{ fontSize: '0.75rem', color: isOverdue(task) ? '#fca5a5' : '#9ca3af' }}>
+                        Due {new Date(task.due_at).toLocaleString()}
+                      </span>
+                    )}
+                  </div>
                 </div>
                 <button
                   onClick={() => deleteTask(task.id)}
@@ -194,6 +325,8 @@ const App: React.FC = () => {
             ))}
           </ul>
         )}
+        </>
+        )}
       </div>
     </div>
   )
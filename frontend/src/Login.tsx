@@ -0,0 +1,64 @@
+import React, { useState } from 'react'
+import { authenticate } from './api'
+
+const inputStyle: React.CSSProperties = {
+  padding: '0.6rem 0.8rem',
+  borderRadius: '0.7rem',
+  border: '1px solid #1f2937',
+  background: '#020617',
+  color: 'white',
+  outline: 'none'
+}
+
+const Login: React.FC<{ onLogin: () => void }> = ({ onLogin }) => {
+  const [signUp, setSignUp] = useState(false)
+  const [name, setName] = useState('')
+  const [email, setEmail] = useState('')
+  const [password, setPassword] = useState('')
+  const [error, setError] = useState<string | null>(null)
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError(null)
+    try {
+      await authenticate(email, password, signUp ? name : undefined)
+      onLogin()
+    } catch (err: any) {
+      setError(err.message || 'Authentication failed')
+    }
+  }
+
+  return (
+    <form onSubmit={handleSubmit} style={{ display: 'flex', flexDirection: 'column', gap: '0.5rem' }}>
+      {signUp && (
+        <input type="text" placeholder="Name" value={name} onChange={e => setName(e.target.value)} style={inputStyle} />
+      )}
+      <input type="email" placeholder="Email" value={email} onChange={e => setEmail(e.target.value)} style={inputStyle} />
+      <input type="password" placeholder="Password" value={password} onChange={e => setPassword(e.target.value)} style={inputStyle} />
+      {error && <span style={{ color: '#fca5a5', fontSize: '0.85rem' }}>{error}</span>}
+      <button
+        type="submit"
+        style={{
+          padding: '0.6rem 1.2rem',
+          borderRadius: '0.7rem',
+          border: 'none',
+          background: '#22c55e',
+          color: '#022c22',
+          fontWeight: 600,
+          cursor: 'pointer'
+        }}
+      >
+        {signUp ? 'Sign up' : 'Log in'}
+      </button>
+      <button
+        type="button"
+        onClick={() => setSignUp(!signUp)}
+        style={{ border: 'none', background: 'transparent', color: '#9ca3af', cursor: 'pointer', fontSize: '0.85rem' }}
+      >
+        {signUp ? 'Already have an account? Log in' : 'No account yet? Sign up'}
+      </button>
+    </form>
+  )
+}
+
+export default Login
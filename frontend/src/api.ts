@@ -0,0 +1,127 @@
+//@ts-expect-error any
+export const API_URL = window._env_?.VITE_API_URL || 'http://localhost:8080';
+
+type Tokens = {
+  access_token: string
+  refresh_token: string
+}
+
+const STORAGE_KEY = 'taskboard.tokens'
+
+export const getTokens = (): Tokens | null => {
+  const raw = localStorage.getItem(STORAGE_KEY)
+  return raw ? JSON.parse(raw) : null
+}
+
+export const setTokens = (tokens: Tokens | null) => {
+  if (tokens) localStorage.setItem(STORAGE_KEY, JSON.stringify(tokens))
+  else localStorage.removeItem(STORAGE_KEY)
+}
+
+// Thrown when the session cannot be renewed and the user must log in again.
+export class UnauthorizedError extends Error {}
+
+const refreshTokens = async (tokens: Tokens): Promise<Tokens | null> => {
+  const res = await fetch(`${API_URL}/api/auth/refresh`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ refresh_token: tokens.refresh_token }),
+  })
+  if (!res.ok) return null
+  const renewed = await res.json()
+  setTokens(renewed)
+  return renewed
+}
+
+// apiFetch sends an authenticated request, renewing the access token once
+// when it has expired.
+export const apiFetch = async (url: string, init: RequestInit = {}): Promise<Response> => {
+  const send = (tokens: Tokens | null) =>
+    fetch(url, {
+      ...init,
+      headers: {
+        ...init.headers,
+        ...(tokens ? { Authorization: `Bearer ${tokens.access_token}` } : {}),
+      },
+    })
+
+  let tokens = getTokens()
+  let res = await send(tokens)
+  if (res.status === 401 && tokens) {
+    tokens = await refreshTokens(tokens)
+    if (tokens) res = await send(tokens)
+  }
+  if (res.status === 401) {
+    setTokens(null)
+    throw new UnauthorizedError('Please log in again')
+  }
+  return res
+}
+
+// authenticate logs in, or signs up when a name is given, and stores the tokens.
+export const authenticate = async (email: string, password: string, name?: string) => {
+  const res = await fetch(`${API_URL}/api/auth/${name ? 'register' : 'login'}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(name ? { name, email, password } : { email, password }),
+  })
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}))
+    throw new Error(body.detail || 'Authentication failed')
+  }
+  setTokens(await res.json())
+}
+
+export type StreamEvent = {
+  type: string
+  data: any
+}
+
+// streamEvents follows GET /api/events until signal aborts, calling onEvent
+// for each event. It reads the stream with apiFetch rather than EventSource,
+// which cannot send the Authorization header, and reconnects after errors
+// with the ID of the last event received so that no change is missed.
+export const streamEvents = async (onEvent: (event: StreamEvent) => void, signal: AbortSignal) => {
+  let lastID = ''
+  let retry = 3000
+  while (!signal.aborted) {
+    try {
+      const res = await apiFetch(`${API_URL}/api/events`, {
+        headers: lastID ? { 'Last-Event-ID': lastID } : {},
+        signal,
+      })
+      if (!res.ok || !res.body) throw new Error('Failed to open the event stream')
+
+      const reader = res.body.pipeThrough(new TextDecoderStream()).getReader()
+      let buffer = ''
+      let event: { id?: string; type?: string; data?: string } = {}
+      for (;;) {
+        const { value, done } = await reader.read()
+        if (done) break
+        buffer += value
+        const lines = buffer.split('\n')
+        buffer = lines.pop() ?? ''
+        for (const line of lines) {
+          if (line === '') {
+            if (event.id) lastID = event.id
+            if (event.type) onEvent({ type: event.type, data: JSON.parse(event.data || 'null') })
+            event = {}
+            continue
+          }
+          // Lines starting with a colon are comments, such as heartbeats.
+          const colon = line.indexOf(':')
+          if (colon <= 0) continue
+          const field = line.slice(0, colon)
+          const text = line.slice(colon + 1).replace(/^ /, '')
+          if (field === 'id') event.id = text
+          else if (field === 'event') event.type = text
+          else if (field === 'data') event.data = text
+          else if (field === 'retry') retry = Number(text) || retry
+        }
+      }
+    } catch (err) {
+      if (signal.aborted || err instanceof UnauthorizedError) return
+    }
+    await new Promise(resolve => setTimeout(resolve, retry))
+  }
+}